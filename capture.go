@@ -0,0 +1,82 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/wathiede/surfer/htmlutil"
+	"github.com/wathiede/surfer/modem"
+)
+
+// capture implements the "capture" command, which saves every page the
+// detected modem's driver reads into a fixture directory.  The directory can
+// be passed to -fake to replay it.
+func capture(ctx context.Context, c *config, args []string) error {
+	fs := flag.NewFlagSet("capture", flag.ContinueOnError)
+	dir := fs.String("dir", "", "directory to write the pages and manifest to.  (default) <model>-<timestamp>")
+	redact := fs.Bool("redact", true, "replace MAC addresses, serial numbers, config file names and public IPs in the captured pages")
+	name := fs.String("modem", "", "name of the modem in the config's modems.  (default) the first")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	mc, err := c.target(*name)
+	if err != nil {
+		return err
+	}
+	ctx = mc.context(ctx)
+	client := newClient(mc)
+
+	m := detectOnce(ctx, client, mc, c.Timeout)
+	if m == nil {
+		return fmt.Errorf("failed to find modem%s", describe(mc))
+	}
+	if *dir == "" {
+		name := strings.Map(func(r rune) rune {
+			if r == ' ' || r == '/' || r == '!' {
+				return '_'
+			}
+			return r
+		}, m.Name())
+		*dir = name + "-" + time.Now().Format("20060102-150405")
+	}
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		return err
+	}
+	var transform func([]byte) []byte
+	if *redact {
+		// Share one Redactor so an identifier gets the same placeholder on
+		// every page.
+		transform = htmlutil.NewRedactor().Redact
+	}
+	man, err := modem.Capture(ctx, client, m, *dir, c.Timeout, transform)
+	if err != nil {
+		return err
+	}
+	for _, p := range man.Pages {
+		if p.Error != "" {
+			fmt.Printf("%s: %s\n", p.URL, p.Error)
+			continue
+		}
+		fmt.Printf("%s -> %s\n", p.URL, p.File)
+	}
+	fmt.Printf("Captured %s to %s\n", man.Modem, *dir)
+	return nil
+}
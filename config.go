@@ -0,0 +1,342 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/modem/health"
+	"github.com/wathiede/surfer/sink/otlp"
+)
+
+// config is how surfer is configured: the flags, overridden by the YAML file
+// passed to -config, in turn overridden by flags given on the command line.
+// Each field documents the flag it corresponds to.
+type config struct {
+	Port            int           `yaml:"port"`             // -port
+	Timeout         time.Duration `yaml:"timeout"`          // -timeout
+	PollInterval    time.Duration `yaml:"poll_interval"`    // -poll_interval
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"` // -shutdown_timeout
+	// HealthThresholds is a path, see -health_thresholds.
+	HealthThresholds string `yaml:"health_thresholds"`
+	// Modem is the modem to poll, unless Modems lists several.
+	Modem   modemConfig   `yaml:"modem"`
+	Modems  []modemConfig `yaml:"modems"`
+	History historyConfig `yaml:"history"`
+	Store   storeConfig   `yaml:"store"`
+	Sinks   sinksConfig   `yaml:"sinks"`
+
+	// thresholds are read from HealthThresholds by validate.
+	thresholds health.Thresholds
+}
+
+type modemConfig struct {
+	// Name tells the modem apart from others in outputs and the API.
+	// It's required in Modems.
+	Name                  string `yaml:"name"`
+	Fake                  string `yaml:"fake"`                     // -fake
+	Simulate              bool   `yaml:"simulate"`                 // -fake_simulate
+	Username              string `yaml:"username"`                 // -username
+	Password              string `yaml:"password"`                 // -password
+	TLSInsecureSkipVerify bool   `yaml:"tls_insecure_skip_verify"` // -tls_insecure_skip_verify
+}
+
+type historyConfig struct {
+	Window  time.Duration `yaml:"window"`  // -history_window
+	Samples int           `yaml:"samples"` // -history_samples
+}
+
+type storeConfig struct {
+	Dir       string        `yaml:"dir"`       // -store_dir
+	Retention time.Duration `yaml:"retention"` // -store_retention
+}
+
+type sinksConfig struct {
+	Prometheus  bool              `yaml:"prometheus"` // -prometheus
+	JSONLog     string            `yaml:"json_log"`   // -json_log
+	CSVLog      string            `yaml:"csv_log"`    // -csv_log
+	LogRotation logRotationConfig `yaml:"log_rotation"`
+	Influx      influxConfig      `yaml:"influx"`
+	MQTT        mqttConfig        `yaml:"mqtt"`
+	OTLP        otlpConfig        `yaml:"otlp"`
+	Graphite    graphiteConfig    `yaml:"graphite"`
+}
+
+type logRotationConfig struct {
+	Every time.Duration `yaml:"every"` // -log_rotate_every
+	// MaxSize is in megabytes.
+	MaxSize    int  `yaml:"max_size"`    // -log_max_size
+	MaxBackups int  `yaml:"max_backups"` // -log_max_backups
+	Compress   bool `yaml:"compress"`    // -log_compress
+}
+
+type influxConfig struct {
+	URL    string `yaml:"url"`    // -influx_url
+	Org    string `yaml:"org"`    // -influx_org
+	Bucket string `yaml:"bucket"` // -influx_bucket
+	Token  string `yaml:"token"`  // -influx_token
+}
+
+type mqttConfig struct {
+	Broker          string `yaml:"broker"`           // -mqtt_broker
+	Username        string `yaml:"username"`         // -mqtt_username
+	Password        string `yaml:"password"`         // -mqtt_password
+	TopicPrefix     string `yaml:"topic_prefix"`     // -mqtt_topic_prefix
+	DiscoveryPrefix string `yaml:"discovery_prefix"` // -mqtt_discovery_prefix
+}
+
+type otlpConfig struct {
+	Endpoint           string            `yaml:"endpoint"`            // -otlp_endpoint
+	Headers            map[string]string `yaml:"headers"`             // -otlp_headers
+	ResourceAttributes map[string]string `yaml:"resource_attributes"` // -otlp_resource_attributes
+}
+
+type graphiteConfig struct {
+	Addr       string `yaml:"addr"`        // -graphite_addr
+	StatsDAddr string `yaml:"statsd_addr"` // -statsd_addr
+	Prefix     string `yaml:"prefix"`      // -graphite_prefix
+}
+
+// configFlags sets the config field of each flag from the flag's value.
+var configFlags = map[string]func(c *config) error{
+	"port":                     func(c *config) error { c.Port = *port; return nil },
+	"timeout":                  func(c *config) error { c.Timeout = *timeout; return nil },
+	"poll_interval":            func(c *config) error { c.PollInterval = *pollInterval; return nil },
+	"shutdown_timeout":         func(c *config) error { c.ShutdownTimeout = *shutdownTimeout; return nil },
+	"health_thresholds":        func(c *config) error { c.HealthThresholds = *healthThresholdsPath; return nil },
+	"fake":                     func(c *config) error { c.Modem.Fake = *fakeDataPath; return nil },
+	"fake_simulate":            func(c *config) error { c.Modem.Simulate = *fakeSimulate; return nil },
+	"username":                 func(c *config) error { c.Modem.Username = *username; return nil },
+	"password":                 func(c *config) error { c.Modem.Password = *password; return nil },
+	"tls_insecure_skip_verify": func(c *config) error { c.Modem.TLSInsecureSkipVerify = *tlsInsecureSkipVerify; return nil },
+	"history_window":           func(c *config) error { c.History.Window = *historyWindow; return nil },
+	"history_samples":          func(c *config) error { c.History.Samples = *historySamples; return nil },
+	"store_dir":                func(c *config) error { c.Store.Dir = *storeDir; return nil },
+	"store_retention":          func(c *config) error { c.Store.Retention = *storeRetention; return nil },
+	"prometheus":               func(c *config) error { c.Sinks.Prometheus = *prometheusEnabled; return nil },
+	"json_log":                 func(c *config) error { c.Sinks.JSONLog = *jsonLogPath; return nil },
+	"csv_log":                  func(c *config) error { c.Sinks.CSVLog = *csvLogPath; return nil },
+	"log_rotate_every":         func(c *config) error { c.Sinks.LogRotation.Every = *logRotateEvery; return nil },
+	"log_max_size":             func(c *config) error { c.Sinks.LogRotation.MaxSize = *logMaxSize; return nil },
+	"log_max_backups":          func(c *config) error { c.Sinks.LogRotation.MaxBackups = *logMaxBackups; return nil },
+	"log_compress":             func(c *config) error { c.Sinks.LogRotation.Compress = *logCompress; return nil },
+	"influx_url":               func(c *config) error { c.Sinks.Influx.URL = *influxURL; return nil },
+	"influx_org":               func(c *config) error { c.Sinks.Influx.Org = *influxOrg; return nil },
+	"influx_bucket":            func(c *config) error { c.Sinks.Influx.Bucket = *influxBucket; return nil },
+	"influx_token":             func(c *config) error { c.Sinks.Influx.Token = *influxToken; return nil },
+	"mqtt_broker":              func(c *config) error { c.Sinks.MQTT.Broker = *mqttBroker; return nil },
+	"mqtt_username":            func(c *config) error { c.Sinks.MQTT.Username = *mqttUsername; return nil },
+	"mqtt_password":            func(c *config) error { c.Sinks.MQTT.Password = *mqttPassword; return nil },
+	"mqtt_topic_prefix":        func(c *config) error { c.Sinks.MQTT.TopicPrefix = *mqttTopicPrefix; return nil },
+	"mqtt_discovery_prefix":    func(c *config) error { c.Sinks.MQTT.DiscoveryPrefix = *mqttDiscoveryPrefix; return nil },
+	"otlp_endpoint":            func(c *config) error { c.Sinks.OTLP.Endpoint = *otlpEndpoint; return nil },
+	"otlp_headers": func(c *config) error {
+		c.Sinks.OTLP.Headers = map[string]string{}
+		return otlp.ParseAttributes(c.Sinks.OTLP.Headers, *otlpHeaders)
+	},
+	"otlp_resource_attributes": func(c *config) error {
+		c.Sinks.OTLP.ResourceAttributes = map[string]string{}
+		return otlp.ParseAttributes(c.Sinks.OTLP.ResourceAttributes, *otlpResource)
+	},
+	"graphite_addr":   func(c *config) error { c.Sinks.Graphite.Addr = *graphiteAddr; return nil },
+	"statsd_addr":     func(c *config) error { c.Sinks.Graphite.StatsDAddr = *statsdAddr; return nil },
+	"graphite_prefix": func(c *config) error { c.Sinks.Graphite.Prefix = *graphitePrefix; return nil },
+}
+
+// applyFlags sets c from the flags for which set returns true.
+func applyFlags(c *config, set func(name string) bool) error {
+	for name, f := range configFlags {
+		if !set(name) {
+			continue
+		}
+		if err := f(c); err != nil {
+			return fmt.Errorf("-%s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// loadConfig returns the config from the flags and, if path isn't empty,
+// the YAML file at path.  Unknown fields in the file are errors.
+func loadConfig(path string) (*config, error) {
+	c := &config{}
+	if err := applyFlags(c, func(string) bool { return true }); err != nil {
+		return nil, err
+	}
+	if path != "" {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		d := yaml.NewDecoder(bytes.NewReader(b))
+		d.KnownFields(true)
+		// An empty file is an empty config.
+		if err := d.Decode(c); err != nil && len(bytes.TrimSpace(b)) > 0 {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		given := map[string]bool{}
+		flag.Visit(func(f *flag.Flag) { given[f.Name] = true })
+		if err := applyFlags(c, func(name string) bool { return given[name] }); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.validate(); err != nil {
+		if path != "" {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// validate checks c for values that can't work, and reads the health
+// thresholds.
+func (c *config) validate() error {
+	switch {
+	case c.Port <= 0 || c.Port > 65535:
+		return fmt.Errorf("port %d out of range", c.Port)
+	case c.Timeout <= 0:
+		return fmt.Errorf("timeout must be positive")
+	case c.PollInterval < 0:
+		return fmt.Errorf("poll_interval must not be negative")
+	case c.ShutdownTimeout < 0:
+		return fmt.Errorf("shutdown_timeout must not be negative")
+	case c.History.Window <= 0 || c.History.Samples <= 0:
+		return fmt.Errorf("history.window and history.samples must be positive")
+	case c.Store.Dir != "" && c.Store.Retention <= 0:
+		return fmt.Errorf("store.retention must be positive")
+	case c.Sinks.LogRotation.Every < 0 || c.Sinks.LogRotation.MaxSize < 0 || c.Sinks.LogRotation.MaxBackups < 0:
+		return fmt.Errorf("sinks.log_rotation values must not be negative")
+	case c.Sinks.JSONLog != "" && c.Sinks.JSONLog == c.Sinks.CSVLog:
+		return fmt.Errorf("sinks.json_log and sinks.csv_log must be different files")
+	case c.Sinks.MQTT.Broker != "" && c.Sinks.MQTT.TopicPrefix == "":
+		return fmt.Errorf("sinks.mqtt.topic_prefix is required")
+	case c.Sinks.MQTT.Password != "" && c.Sinks.MQTT.Username == "":
+		return fmt.Errorf("sinks.mqtt.password requires sinks.mqtt.username")
+	}
+	if err := c.validateModems(); err != nil {
+		return err
+	}
+	if c.Sinks.Influx.URL != "" {
+		u, err := url.Parse(c.Sinks.Influx.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("sinks.influx.url %q must be an http or https URL", c.Sinks.Influx.URL)
+		}
+		if c.Sinks.Influx.Bucket == "" {
+			return fmt.Errorf("sinks.influx.bucket is required")
+		}
+	}
+	if c.Sinks.OTLP.Endpoint != "" {
+		if _, err := otlp.Endpoint(c.Sinks.OTLP.Endpoint); err != nil {
+			return fmt.Errorf("sinks.otlp.endpoint: %v", err)
+		}
+		// The exporter pushes the prometheus metrics, which are only
+		// updated by the prometheus sink.
+		if !c.Sinks.Prometheus {
+			return fmt.Errorf("sinks.otlp requires sinks.prometheus")
+		}
+	}
+	c.thresholds = health.DOCSIS()
+	if c.HealthThresholds != "" {
+		var err error
+		if c.thresholds, err = health.ReadThresholds(c.HealthThresholds); err != nil {
+			return fmt.Errorf("health_thresholds: %v", err)
+		}
+	}
+	return nil
+}
+
+// validateModems checks the modem, or each of the modems.
+func (c *config) validateModems() error {
+	if len(c.Modems) == 0 {
+		if c.Modem.Simulate && c.Modem.Fake == "" {
+			return fmt.Errorf("modem.simulate requires modem.fake")
+		}
+		return nil
+	}
+	// The modem flags all default to zero.
+	if c.Modem != (modemConfig{}) {
+		return fmt.Errorf("modem, or its flags, can't be combined with modems")
+	}
+	names := map[string]bool{}
+	for i, m := range c.Modems {
+		switch {
+		case m.Name == "":
+			return fmt.Errorf("modems[%d].name is required", i)
+		case m.Name == "." || m.Name == ".." || strings.ContainsAny(m.Name, `/\`):
+			// Names are directories in the store.
+			return fmt.Errorf("modems[%d].name %q must not be a path", i, m.Name)
+		case names[m.Name]:
+			return fmt.Errorf("modems[%d].name %q is used more than once", i, m.Name)
+		case m.Simulate && m.Fake == "":
+			return fmt.Errorf("modems[%d].simulate requires modems[%d].fake", i, i)
+		}
+		names[m.Name] = true
+	}
+	return nil
+}
+
+// targets returns the modems to poll.
+func (c *config) targets() []modemConfig {
+	if len(c.Modems) == 0 {
+		return []modemConfig{c.Modem}
+	}
+	return c.Modems
+}
+
+// target returns the modem named name, or the first if name is empty.
+func (c *config) target(name string) (modemConfig, error) {
+	ts := c.targets()
+	if name == "" {
+		return ts[0], nil
+	}
+	for _, m := range ts {
+		if m.Name == name {
+			return m, nil
+		}
+	}
+	return modemConfig{}, fmt.Errorf("no modem named %q", name)
+}
+
+// context returns ctx carrying the modem credentials, if any.
+func (m modemConfig) context(ctx context.Context) context.Context {
+	if m.Username == "" && m.Password == "" {
+		return ctx
+	}
+	return modem.WithCredentials(ctx, modem.Credentials{
+		Username: m.Username,
+		Password: m.Password,
+	})
+}
+
+// storeDir returns the directory m's history is stored in under dir: dir
+// itself for an unnamed modem, else a directory named after it.
+func (m modemConfig) storeDir(dir string) string {
+	if m.Name == "" {
+		return dir
+	}
+	return filepath.Join(dir, m.Name)
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, s string) string {
+	f, err := ioutil.TempFile("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(s)
+	f.Close()
+	return f.Name()
+}
+
+func TestLoadConfig(t *testing.T) {
+	p := writeConfig(t, `
+poll_interval: 30s
+modem:
+  username: admin
+sinks:
+  csv_log: /var/log/surfer.csv
+  log_rotation:
+    max_backups: 7
+  otlp:
+    endpoint: http://localhost:4318
+    headers:
+      authorization: Bearer x
+`)
+	defer os.Remove(p)
+	c, err := loadConfig(p)
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	// Fields missing from the file keep their flag defaults.
+	if c.Port != 6666 || c.Timeout != time.Second || c.History.Samples != 10000 {
+		t.Errorf("Got port %d timeout %s history samples %d, want flag defaults", c.Port, c.Timeout, c.History.Samples)
+	}
+	if c.PollInterval != 30*time.Second || c.Modem.Username != "admin" {
+		t.Errorf("Got poll interval %s username %q, want 30s admin", c.PollInterval, c.Modem.Username)
+	}
+	want := sinksConfig{
+		Prometheus:  true,
+		CSVLog:      "/var/log/surfer.csv",
+		LogRotation: logRotationConfig{Every: 24 * time.Hour, MaxSize: 100, MaxBackups: 7, Compress: true},
+		Influx:      influxConfig{Bucket: "surfer"},
+		MQTT:        mqttConfig{TopicPrefix: "surfer", DiscoveryPrefix: "homeassistant"},
+		OTLP: otlpConfig{
+			Endpoint:           "http://localhost:4318",
+			Headers:            map[string]string{"authorization": "Bearer x"},
+			ResourceAttributes: map[string]string{},
+		},
+		Graphite: graphiteConfig{Prefix: "surfer"},
+	}
+	if !reflect.DeepEqual(c.Sinks, want) {
+		t.Errorf("Sinks\n got %+v\nwant %+v", c.Sinks, want)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	for _, tc := range []struct {
+		config, want string
+	}{
+		{"port: 70000", "port 70000 out of range"},
+		{"prot: 6667", "field prot not found"},
+		{"timeout: soon", "cannot unmarshal"},
+		{"modem: {simulate: true}", "modem.simulate requires modem.fake"},
+		{"modems: [{fake: a}]", "modems[0].name is required"},
+		{"modems: [{name: a}, {name: a}]", `modems[1].name "a" is used more than once`},
+		{"modems: [{name: ../a}]", "must not be a path"},
+		{"modems: [{name: a, simulate: true}]", "modems[0].simulate requires modems[0].fake"},
+		{"modem: {username: admin}\nmodems: [{name: a}]", "can't be combined with modems"},
+		{"sinks: {json_log: a.log, csv_log: a.log}", "must be different files"},
+		{"sinks: {influx: {url: localhost:8086}}", "must be an http or https URL"},
+		{"sinks: {mqtt: {broker: localhost, password: secret}}", "requires sinks.mqtt.username"},
+		{"sinks: {prometheus: false, otlp: {endpoint: \"http://localhost:4318\"}}", "sinks.otlp requires sinks.prometheus"},
+		{"health_thresholds: /nonexistent", "health_thresholds"},
+	} {
+		p := writeConfig(t, tc.config)
+		_, err := loadConfig(p)
+		os.Remove(p)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("loadConfig(%q) got error %v, want one containing %q", tc.config, err, tc.want)
+		}
+	}
+}
+
+func TestLoadConfigFlagsOverride(t *testing.T) {
+	p := writeConfig(t, "port: 7000\ntimeout: 5s\n")
+	defer os.Remove(p)
+	defer flag.Set("port", "6666")
+	flag.Set("port", "7001")
+	c, err := loadConfig(p)
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	if c.Port != 7001 || c.Timeout != 5*time.Second {
+		t.Errorf("Got port %d timeout %s, want 7001 5s", c.Port, c.Timeout)
+	}
+}
+
+func TestConfigTarget(t *testing.T) {
+	p := writeConfig(t, `
+modems:
+- name: upstairs
+  fake: a.html
+- name: downstairs
+  username: admin
+`)
+	defer os.Remove(p)
+	c, err := loadConfig(p)
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	for _, tc := range []struct {
+		name string
+		want modemConfig
+	}{
+		{"", modemConfig{Name: "upstairs", Fake: "a.html"}},
+		{"downstairs", modemConfig{Name: "downstairs", Username: "admin"}},
+	} {
+		got, err := c.target(tc.name)
+		if err != nil {
+			t.Errorf("target(%q) failed: %v", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("target(%q) got %+v want %+v", tc.name, got, tc.want)
+		}
+	}
+	if _, err := c.target("attic"); err == nil {
+		t.Errorf("target of a missing modem succeeded")
+	}
+	if got, want := (modemConfig{Name: "upstairs"}).storeDir("/var/lib/surfer"), "/var/lib/surfer/upstairs"; got != want {
+		t.Errorf("storeDir got %q want %q", got, want)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/modem/health"
+)
+
+// webFS holds the dashboard served at /.
+//
+//go:embed web
+var webFS embed.FS
+
+// dashboard returns an http.Handler serving the dashboard's files.
+func dashboard() http.Handler {
+	sub, err := fs.Sub(webFS, "web")
+	if err != nil {
+		panic(err)
+	}
+	return http.FileServer(http.FS(sub))
+}
+
+// latestStatus holds the most recent status read from the modem, served as
+// JSON to the dashboard.
+type latestStatus struct {
+	mu     sync.Mutex
+	report *statusReport
+	err    error
+}
+
+func (l *latestStatus) set(name string, t time.Time, s *modem.Signal, h *health.Report) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.report = &statusReport{Modem: name, Time: t, Signal: s, Health: h}
+	l.err = nil
+}
+
+func (l *latestStatus) setError(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.err = err
+}
+
+// handler returns an http.Handler that polls the modem with poll, then
+// serves the latest status.  If the poll fails, the previous status is
+// served with the error.
+func (l *latestStatus) handler(poll func() error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := poll(); err != nil {
+			l.setError(err)
+		}
+		l.mu.Lock()
+		var report statusReport
+		if l.report != nil {
+			report = *l.report
+		}
+		err := l.err
+		l.mu.Unlock()
+		if report.Signal == nil {
+			msg := "No status read yet"
+			if err != nil {
+				msg = err.Error()
+			}
+			http.Error(w, msg, http.StatusBadGateway)
+			return
+		}
+		if err != nil {
+			report.Error = err.Error()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
@@ -1,13 +1,16 @@
 module github.com/wathiede/surfer
 
-go 1.12
+go 1.16
 
 require (
 	github.com/andybalholm/cascadia v1.3.1
 	github.com/golang/glog v1.0.0
 	github.com/golang/groupcache v0.0.0-20210331224755-41bb18bfe9da
 	github.com/prometheus/client_golang v1.14.0
+	github.com/prometheus/client_model v0.3.0
 	github.com/prometheus/common v0.39.0 // indirect
 	github.com/prometheus/procfs v0.9.0 // indirect
+	go.etcd.io/bbolt v1.3.5
 	golang.org/x/net v0.33.0
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,231 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/modem/history"
+	"github.com/wathiede/surfer/modem/store"
+)
+
+// showHistory implements the "history" command, which prints the signal and
+// events recorded in -store_dir.  It opens the store read-only, so it works
+// after the modem is gone and never expires records.  While surfer is serving
+// from the store, it is locked, and the range is fetched from surfer's HTTP
+// API on -port instead.
+func showHistory(c *config, args []string) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	from := fs.String("from", "", "start of the range, an RFC 3339 time or Unix seconds.  (default) -store_retention ago")
+	to := fs.String("to", "", "end of the range, an RFC 3339 time or Unix seconds.  (default) now")
+	channel := fs.String("channel", "", "only show this channel")
+	points := fs.Int("points", 500, "most points per channel, longer ranges are downsampled.  0 shows every sample")
+	format := fs.String("format", "table", "output format, one of: table, json")
+	name := fs.String("modem", "", "name of the modem in the config's modems.  (default) the first")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if c.Store.Dir == "" {
+		return fmt.Errorf("-store_dir is required")
+	}
+	mc, err := c.target(*name)
+	if err != nil {
+		return err
+	}
+	if *format != "table" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	end := time.Now()
+	if *to != "" {
+		var err error
+		if end, err = history.ParseTime(*to); err != nil {
+			return fmt.Errorf("invalid -to: %v", err)
+		}
+	}
+	start := end.Add(-c.Store.Retention)
+	if *from != "" {
+		var err error
+		if start, err = history.ParseTime(*from); err != nil {
+			return fmt.Errorf("invalid -from: %v", err)
+		}
+	}
+
+	var series *history.Series
+	var events []store.Record
+	st, err := store.OpenReadOnly(mc.storeDir(c.Store.Dir), c.Store.Retention)
+	switch {
+	case errors.Is(err, store.ErrLocked):
+		glog.Infof("%v, asking the surfer serving on port %d", err, c.Port)
+		series, events, err = fetchHistory(fmt.Sprintf("http://localhost:%d", c.Port), mc.Name, start, end, *channel, *points)
+		if err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	default:
+		defer st.Close()
+		records, err := st.Records(start, end)
+		if err != nil {
+			return err
+		}
+		var samples []history.Sample
+		for _, r := range records {
+			if r.Signal != nil {
+				samples = append(samples, history.Sample{Time: r.Time, Signal: r.Signal})
+			} else {
+				events = append(events, r)
+			}
+		}
+		series = history.NewSeries(samples, start, end, modem.Channel(*channel), *points)
+	}
+	if *format == "json" {
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "  ")
+		return e.Encode(struct {
+			*history.Series
+			Events []store.Record `json:"events"`
+		}{series, events})
+	}
+	return writeHistoryTable(os.Stdout, series, events)
+}
+
+// fetchHistory gets the series and events of the modem named name, or the
+// first if name is empty, from start to end from the /api/v1/history and
+// /api/v1/events endpoints of the surfer serving at base.
+func fetchHistory(base, name string, start, end time.Time, channel string, points int) (*history.Series, []store.Record, error) {
+	q := url.Values{
+		"from":   {start.Format(time.RFC3339Nano)},
+		"to":     {end.Format(time.RFC3339Nano)},
+		"points": {strconv.Itoa(points)},
+	}
+	if name != "" {
+		q.Set("modem", name)
+	}
+	var series history.Series
+	var events []store.Record
+	if err := getJSON(base+"/api/v1/events?"+q.Encode(), &events); err != nil {
+		return nil, nil, err
+	}
+	if channel != "" {
+		q.Set("channel", channel)
+	}
+	if err := getJSON(base+"/api/v1/history?"+q.Encode(), &series); err != nil {
+		return nil, nil, err
+	}
+	return &series, events, nil
+}
+
+func getJSON(u string, v interface{}) error {
+	resp, err := http.Get(u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("GET %s: %s: %s", u, resp.Status, bytes.TrimSpace(b))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// historyRow is a line of a channel's values in a history table.
+type historyRow struct {
+	t    time.Time
+	ch   modem.Channel
+	line string
+}
+
+// writeHistoryRows writes rows in time order, then channel order, under
+// title and header.
+func writeHistoryRows(w io.Writer, title, header string, rows []historyRow) error {
+	var chs []modem.Channel
+	seen := map[modem.Channel]bool{}
+	for _, r := range rows {
+		if !seen[r.ch] {
+			seen[r.ch] = true
+			chs = append(chs, r.ch)
+		}
+	}
+	modem.SortChannels(chs)
+	rank := map[modem.Channel]int{}
+	for i, ch := range chs {
+		rank[ch] = i
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].t.Equal(rows[j].t) {
+			return rows[i].t.Before(rows[j].t)
+		}
+		return rank[rows[i].ch] < rank[rows[j].ch]
+	})
+	fmt.Fprintf(w, "%s\n", title)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, header)
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.t.Local().Format(time.RFC3339), r.ch, r.line)
+	}
+	return tw.Flush()
+}
+
+func writeHistoryTable(w io.Writer, s *history.Series, events []store.Record) error {
+	var rows []historyRow
+	for ch, points := range s.Downstream {
+		for _, p := range points {
+			rows = append(rows, historyRow{p.Time, ch, fmt.Sprintf("%.1f\t%.1f\t%.0f\t%.0f\t%.0f\t",
+				p.PowerLevel, p.SNR, p.Unerrored, p.Correctable, p.Uncorrectable)})
+		}
+	}
+	if err := writeHistoryRows(w, "Downstream", "Time\tChannel\tPower (dBmV)\tSNR (dB)\tUnerrored\tCorrectable\tUncorrectable\t", rows); err != nil {
+		return err
+	}
+
+	rows = nil
+	for ch, points := range s.Upstream {
+		for _, p := range points {
+			rows = append(rows, historyRow{p.Time, ch, fmt.Sprintf("%.1f\t%.0f\t", p.PowerLevel, p.SymbolRate)})
+		}
+	}
+	fmt.Fprintln(w)
+	if err := writeHistoryRows(w, "Upstream", "Time\tChannel\tPower (dBmV)\tSymbol Rate\t", rows); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\nEvents\n")
+	for _, e := range events {
+		switch {
+		case e.Reboot != nil:
+			fmt.Fprintf(w, "%s  reboot at %s: %s\n", e.Time.Local().Format(time.RFC3339), e.Reboot.Time.Local().Format(time.RFC3339), e.Reboot.Reason)
+		case e.ChannelEvent != nil:
+			c := e.ChannelEvent
+			fmt.Fprintf(w, "%s  %s channel %s %s %s -> %s\n", e.Time.Local().Format(time.RFC3339), c.Direction, c.Channel, c.Kind, c.Old, c.New)
+		}
+	}
+	return nil
+}
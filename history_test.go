@@ -0,0 +1,121 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/modem/changes"
+	"github.com/wathiede/surfer/modem/history"
+	"github.com/wathiede/surfer/modem/reboot"
+	"github.com/wathiede/surfer/modem/store"
+)
+
+func testHistory() (*history.Series, []store.Record) {
+	t0 := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	t1 := t0.Add(time.Minute)
+	s := &history.Series{
+		From: t0,
+		To:   t1,
+		Downstream: map[modem.Channel][]history.DownstreamPoint{
+			"10": {{Time: t0, PowerLevel: 1.5, SNR: 38.2, Correctable: 3}},
+			"2":  {{Time: t0, PowerLevel: 2.5, SNR: 39.1}, {Time: t1, PowerLevel: 2.4, SNR: 39, Uncorrectable: 1}},
+		},
+		Upstream: map[modem.Channel][]history.UpstreamPoint{
+			"1": {{Time: t0, PowerLevel: 41.5, SymbolRate: 5.12e6}},
+		},
+	}
+	events := []store.Record{
+		{Time: t0, Reboot: &reboot.Event{Time: t0.Add(-time.Minute), Reason: "uptime reset"}},
+		{Time: t1, ChannelEvent: &changes.Event{Time: t1, Direction: changes.Downstream, Channel: "10", Kind: changes.Added, New: "603000000"}},
+	}
+	return s, events
+}
+
+func TestWriteHistoryTable(t *testing.T) {
+	defer func(l *time.Location) { time.Local = l }(time.Local)
+	time.Local = time.UTC
+	s, events := testHistory()
+	var b bytes.Buffer
+	if err := writeHistoryTable(&b, s, events); err != nil {
+		t.Fatalf("writeHistoryTable failed: %v", err)
+	}
+	want := `Downstream
+                  Time  Channel  Power (dBmV)  SNR (dB)  Unerrored  Correctable  Uncorrectable
+  2026-01-02T03:04:05Z        2           2.5      39.1          0            0              0
+  2026-01-02T03:04:05Z       10           1.5      38.2          0            3              0
+  2026-01-02T03:05:05Z        2           2.4      39.0          0            0              1
+
+Upstream
+                  Time  Channel  Power (dBmV)  Symbol Rate
+  2026-01-02T03:04:05Z        1          41.5      5120000
+
+Events
+2026-01-02T03:04:05Z  reboot at 2026-01-02T03:03:05Z: uptime reset
+2026-01-02T03:05:05Z  downstream channel 10 added  -> 603000000
+`
+	if got := b.String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+}
+
+func TestFetchHistory(t *testing.T) {
+	s, events := testHistory()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.FormValue("points"), "10"; got != want {
+			t.Errorf("%s: Got points %q want %q", r.URL.Path, got, want)
+		}
+		if got, want := r.FormValue("modem"), "upstairs"; got != want {
+			t.Errorf("%s: Got modem %q want %q", r.URL.Path, got, want)
+		}
+		if _, err := history.ParseTime(r.FormValue("from")); err != nil {
+			t.Errorf("%s: Bad from: %v", r.URL.Path, err)
+		}
+		switch r.URL.Path {
+		case "/api/v1/history":
+			if got, want := r.FormValue("channel"), "2"; got != want {
+				t.Errorf("Got channel %q want %q", got, want)
+			}
+			json.NewEncoder(w).Encode(s)
+		case "/api/v1/events":
+			json.NewEncoder(w).Encode(events)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	gotSeries, gotEvents, err := fetchHistory(srv.URL, "upstairs", s.From, s.To, "2", 10)
+	if err != nil {
+		t.Fatalf("fetchHistory failed: %v", err)
+	}
+	if !reflect.DeepEqual(gotSeries, s) {
+		t.Errorf("Got series %+v want %+v", gotSeries, s)
+	}
+	if !reflect.DeepEqual(gotEvents, events) {
+		t.Errorf("Got events %+v want %+v", gotEvents, events)
+	}
+
+	if _, _, err := fetchHistory(srv.URL+"/missing", "upstairs", s.From, s.To, "", 10); err == nil {
+		t.Errorf("fetchHistory of a missing endpoint succeeded")
+	}
+}
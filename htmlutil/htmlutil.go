@@ -19,3 +19,24 @@ func GetText(n *html.Node) string {
 
 	return strings.TrimSpace(strings.Join(text, ""))
 }
+
+// RowValue returns the text of the cell following the first td in n whose
+// text is label, as in product information tables like
+// <tr><td>Up Time</td><td>7 days 04h:12m:34s</td></tr>.  The boolean is false
+// if no such cell is found.
+func RowValue(n *html.Node, label string) (string, bool) {
+	if n.Type == html.ElementNode && n.Data == "td" && GetText(n) == label {
+		for c := n.NextSibling; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && c.Data == "td" {
+				return GetText(c), true
+			}
+		}
+		return "", false
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if v, ok := RowValue(c, label); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
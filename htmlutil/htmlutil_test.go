@@ -0,0 +1,50 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package htmlutil
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestRowValue(t *testing.T) {
+	n, err := html.Parse(strings.NewReader(`<table>
+<tr><th colspan=2>Status</th></tr>
+<tr><td>Up Time</td><td> 7 days 04h:12m:34s </td></tr>
+<tr><td><b>Software Version</b></td><td>1.2.3</td></tr>
+<tr><td>Empty</td></tr>
+</table>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		label string
+		want  string
+		ok    bool
+	}{
+		{"Up Time", "7 days 04h:12m:34s", true},
+		{"Software Version", "1.2.3", true},
+		{"Empty", "", false},
+		{"Status", "", false},
+		{"Missing", "", false},
+	} {
+		got, ok := RowValue(n, tc.label)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("RowValue(%q): Got %q, %v want %q, %v", tc.label, got, ok, tc.want, tc.ok)
+		}
+	}
+}
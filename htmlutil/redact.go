@@ -0,0 +1,165 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package htmlutil
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"regexp"
+)
+
+var (
+	macRE = regexp.MustCompile(`[0-9A-Fa-f]{2}([:-])[0-9A-Fa-f]{2}(?:[:-][0-9A-Fa-f]{2}){4}`)
+	ipRE  = regexp.MustCompile(`(?:[0-9]{1,3}\.){3}[0-9]{1,3}`)
+	cfgRE = regexp.MustCompile(`[\w.\-/]+\.cfg`)
+	// serialRE matches a "Serial Number" label, any markup or punctuation
+	// between it and its value, and the value itself.
+	serialRE = regexp.MustCompile(`(?i)(serial\s*(?:number|no\.?)(?:\s|&nbsp;|:|\|)*(?:<[^>]*>(?:\s|&nbsp;|:)*)*)([A-Za-z0-9][A-Za-z0-9\-]{3,})`)
+)
+
+// Redactor replaces identifying values in modem pages with placeholders:
+//   - MAC addresses become addresses in 00:00:5E:00:53:00/24, reserved for
+//     documentation by RFC 7042.
+//   - Public IPv4 addresses become addresses in 203.0.113.0/24, reserved for
+//     documentation by RFC 5737.  Private addresses, such as the modem's own
+//     192.168.100.1, are kept.
+//   - Values labeled as serial numbers become "SERIAL0001" and so on.
+//   - Config file names become "redacted0001.cfg" and so on.
+//
+// Only the matched text is replaced, so the markup around it is preserved and
+// the page parses the same.  Replacement is deterministic: a Redactor maps
+// each distinct value to the same placeholder every time it's seen, across
+// all pages it redacts, in order of first appearance.
+type Redactor struct {
+	seen  map[string]string
+	count map[string]int
+}
+
+// NewRedactor returns a Redactor with no values seen.
+func NewRedactor() *Redactor {
+	return &Redactor{
+		seen:  map[string]string{},
+		count: map[string]int{},
+	}
+}
+
+// Redact returns a copy of b with identifying values replaced using a new
+// Redactor.
+func Redact(b []byte) []byte {
+	return NewRedactor().Redact(b)
+}
+
+// Redact returns a copy of b with identifying values replaced.
+func (r *Redactor) Redact(b []byte) []byte {
+	b = replaceAll(b, macRE, func(m []byte) []byte {
+		sep := string(m[2])
+		return r.placeholder("mac", bytes.ToUpper(m), func(n int) string {
+			return fmt.Sprintf("00%[2]s00%[2]s5E%[2]s00%[2]s53%[2]s%02[1]X", n%256, sep)
+		})
+	})
+	b = replaceAll(b, ipRE, func(m []byte) []byte {
+		ip := net.ParseIP(string(m))
+		if ip == nil || !isPublic(ip) {
+			return m
+		}
+		return r.placeholder("ip", m, func(n int) string {
+			return fmt.Sprintf("203.0.113.%d", n%254+1)
+		})
+	})
+	b = replaceAll(b, cfgRE, func(m []byte) []byte {
+		return r.placeholder("cfg", m, func(n int) string {
+			return fmt.Sprintf("redacted%04d.cfg", n+1)
+		})
+	})
+	return serialRE.ReplaceAllFunc(b, func(m []byte) []byte {
+		sm := serialRE.FindSubmatch(m)
+		label, v := sm[1], sm[2]
+		p := r.placeholder("serial", v, func(n int) string {
+			return fmt.Sprintf("SERIAL%04d", n+1)
+		})
+		return append(append([]byte{}, label...), p...)
+	})
+}
+
+// placeholder returns the replacement for v, calling format with the number
+// of distinct values of kind seen so far if v is new.
+func (r *Redactor) placeholder(kind string, v []byte, format func(n int) string) []byte {
+	k := kind + ":" + string(v)
+	p, ok := r.seen[k]
+	if !ok {
+		p = format(r.count[kind])
+		r.count[kind]++
+		r.seen[k] = p
+	}
+	return []byte(p)
+}
+
+// replaceAll is like re.ReplaceAllFunc, but ignores matches that are part of
+// a longer word, e.g. the "2.4.0.1" in a firmware version "OSPREY-2.4.0.1-GA".
+func replaceAll(b []byte, re *regexp.Regexp, f func([]byte) []byte) []byte {
+	var out []byte
+	last := 0
+	for _, loc := range re.FindAllIndex(b, -1) {
+		start, end := loc[0], loc[1]
+		if (start > 0 && isWord(b[start-1])) || (end < len(b) && isWord(b[end])) {
+			continue
+		}
+		out = append(out, b[last:start]...)
+		out = append(out, f(b[start:end])...)
+		last = end
+	}
+	return append(out, b[last:]...)
+}
+
+func isWord(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' ||
+		c == '.' || c == '-' || c == '_' || c == ':'
+}
+
+var privateNets []*net.IPNet
+
+func init() {
+	for _, s := range []string{
+		"0.0.0.0/8",
+		"10.0.0.0/8",
+		"100.64.0.0/10",
+		"127.0.0.0/8",
+		"169.254.0.0/16",
+		"172.16.0.0/12",
+		"192.0.2.0/24",
+		"192.168.0.0/16",
+		"198.51.100.0/24",
+		"203.0.113.0/24",
+		"224.0.0.0/3",
+	} {
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			panic(err)
+		}
+		privateNets = append(privateNets, n)
+	}
+}
+
+// isPublic reports whether ip is routable on the internet, and so might
+// identify the user.  Subnet masks like 255.255.255.0 fall in 224.0.0.0/3.
+func isPublic(ip net.IP) bool {
+	for _, n := range privateNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
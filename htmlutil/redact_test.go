@@ -0,0 +1,70 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package htmlutil
+
+import "testing"
+
+func TestRedact(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{
+			in:   `<td>HFC MAC Address</td><td>a4:15:88:12:34:56</td><td>A4-15-88-12-34-57</td>`,
+			want: `<td>HFC MAC Address</td><td>00:00:5E:00:53:00</td><td>00-00-5E-00-53-01</td>`,
+		},
+		{
+			// The same MAC in different case maps to the same placeholder.
+			in:   `a4:15:88:12:34:56 A4:15:88:12:34:56`,
+			want: `00:00:5E:00:53:00 00:00:5E:00:53:00`,
+		},
+		{
+			in:   `<td>73.12.44.201</td><td>192.168.100.1</td><td>255.255.255.0</td><td>8.8.8.8</td><td>73.12.44.201</td>`,
+			want: `<td>203.0.113.1</td><td>192.168.100.1</td><td>255.255.255.0</td><td>203.0.113.2</td><td>203.0.113.1</td>`,
+		},
+		{
+			// Version numbers aren't addresses.
+			in:   `<td>D30CM-OSPREY-2.4.0.1-GA-02-NOSH</td>`,
+			want: `<td>D30CM-OSPREY-2.4.0.1-GA-02-NOSH</td>`,
+		},
+		{
+			in:   `<td>Serial Number</td>  <td>&nbsp;3982-A1234567</td>`,
+			want: `<td>Serial Number</td>  <td>&nbsp;SERIAL0001</td>`,
+		},
+		{
+			in:   `Cable Modem Serial Number: 368912345678901234`,
+			want: `Cable Modem Serial Number: SERIAL0001`,
+		},
+		{
+			in:   `<td>Config File Name</td><td>d11_m_sb8200_gigabit_c01.cfg</td>`,
+			want: `<td>Config File Name</td><td>redacted0001.cfg</td>`,
+		},
+		{
+			in:   `<td>QAM256</td><td>639000000 Hz</td><td>1.5 dBmV</td>`,
+			want: `<td>QAM256</td><td>639000000 Hz</td><td>1.5 dBmV</td>`,
+		},
+	} {
+		if got := string(Redact([]byte(tc.in))); got != tc.want {
+			t.Errorf("Redact(%q)\n got %q\nwant %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestRedactorSharesPlaceholders(t *testing.T) {
+	r := NewRedactor()
+	r.Redact([]byte("01:02:03:04:05:06"))
+	if got, want := string(r.Redact([]byte("0a:0b:0c:0d:0e:0f 01:02:03:04:05:06"))), "00:00:5E:00:53:01 00:00:5E:00:53:00"; got != want {
+		t.Errorf("Got %q want %q", got, want)
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package changes tracks the CMTS adding, removing and re-assigning bonded
+// channels, by diffing successive samples of a modem.Signal.
+package changes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+)
+
+// Kind is the kind of change to a channel.
+type Kind string
+
+// Kinds of change.
+const (
+	Added             Kind = "added"
+	Removed           Kind = "removed"
+	FrequencyChanged  Kind = "frequency"
+	ModulationChanged Kind = "modulation"
+)
+
+// Directions of a channel.
+const (
+	Downstream = "downstream"
+	Upstream   = "upstream"
+)
+
+// Event is a change to a single channel.
+type Event struct {
+	Time      time.Time     `json:"time"`
+	Direction string        `json:"direction"`
+	Channel   modem.Channel `json:"channel"`
+	Kind      Kind          `json:"kind"`
+	// Old and New are the frequency or modulation before and after a
+	// change.  For an added or removed channel, they are its frequency.
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+}
+
+// params are the parameters of a channel that the CMTS assigns.
+type params struct {
+	frequency, modulation string
+}
+
+func diff(t time.Time, dir string, prev, cur map[modem.Channel]params) []Event {
+	var events []Event
+	var chs []modem.Channel
+	for ch := range prev {
+		chs = append(chs, ch)
+	}
+	for ch := range cur {
+		if _, ok := prev[ch]; !ok {
+			chs = append(chs, ch)
+		}
+	}
+	modem.SortChannels(chs)
+	for _, ch := range chs {
+		p, inPrev := prev[ch]
+		c, inCur := cur[ch]
+		e := Event{Time: t, Direction: dir, Channel: ch}
+		switch {
+		case !inPrev:
+			e.Kind, e.New = Added, c.frequency
+			events = append(events, e)
+		case !inCur:
+			e.Kind, e.Old = Removed, p.frequency
+			events = append(events, e)
+		default:
+			if p.frequency != c.frequency {
+				e.Kind, e.Old, e.New = FrequencyChanged, p.frequency, c.frequency
+				events = append(events, e)
+			}
+			if p.modulation != c.modulation {
+				e.Kind, e.Old, e.New = ModulationChanged, p.modulation, c.modulation
+				events = append(events, e)
+			}
+		}
+	}
+	return events
+}
+
+func downstreamParams(s *modem.Signal) map[modem.Channel]params {
+	m := map[modem.Channel]params{}
+	for ch, d := range s.Downstream {
+		m[ch] = params{d.Frequency, d.Modulation}
+	}
+	return m
+}
+
+func upstreamParams(s *modem.Signal) map[modem.Channel]params {
+	m := map[modem.Channel]params{}
+	for ch, u := range s.Upstream {
+		m[ch] = params{u.Frequency, u.Modulation}
+	}
+	return m
+}
+
+// Diff returns the changes from prev to cur, stamped with t, downstream
+// first, then in channel order.
+func Diff(t time.Time, prev, cur *modem.Signal) []Event {
+	events := diff(t, Downstream, downstreamParams(prev), downstreamParams(cur))
+	return append(events, diff(t, Upstream, upstreamParams(prev), upstreamParams(cur))...)
+}
+
+// Log records the changes between successive samples, keeping the most
+// recent.  It is safe for concurrent use.
+type Log struct {
+	mu     sync.Mutex
+	max    int
+	prev   *modem.Signal
+	events []Event
+}
+
+// NewLog returns a Log keeping up to max events.
+func NewLog(max int) *Log {
+	return &Log{max: max}
+}
+
+// Update records s as sampled at t, and returns the changes since the
+// previous sample.  The first sample has no changes.
+func (l *Log) Update(t time.Time, s *modem.Signal) []Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	prev := l.prev
+	l.prev = s
+	if prev == nil {
+		return nil
+	}
+	events := Diff(t, prev, s)
+	l.events = append(l.events, events...)
+	if n := len(l.events) - l.max; n > 0 {
+		l.events = append([]Event(nil), l.events[n:]...)
+	}
+	return events
+}
+
+// Events returns the recorded events after since, oldest first.
+func (l *Log) Events(since time.Time) []Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	events := []Event{}
+	for _, e := range l.events {
+		if e.Time.After(since) {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// ServeHTTP serves the recorded events as a JSON array.  The optional since
+// query parameter, an RFC 3339 time, limits them to those after it.
+func (l *Log) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if v := r.FormValue("since"); v != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, v); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid since %q: %v", v, err), http.StatusBadRequest)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(l.Events(since)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package changes
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+)
+
+func TestLog(t *testing.T) {
+	t0 := time.Unix(1000, 0).UTC()
+	t1 := t0.Add(time.Minute)
+	prev := &modem.Signal{
+		Downstream: map[modem.Channel]*modem.Downstream{
+			"1": {Frequency: "591000000", Modulation: "QAM256"},
+			"2": {Frequency: "597000000", Modulation: "QAM256"},
+			"3": {Frequency: "603000000", Modulation: "QAM256"},
+		},
+		Upstream: map[modem.Channel]*modem.Upstream{
+			"1": {Frequency: "38600000", Modulation: "64QAM"},
+		},
+	}
+	cur := &modem.Signal{
+		Downstream: map[modem.Channel]*modem.Downstream{
+			"1":  {Frequency: "591000000", Modulation: "QAM256"},
+			"2":  {Frequency: "609000000", Modulation: "QAM64"},
+			"10": {Frequency: "651000000", Modulation: "QAM256"},
+		},
+		Upstream: map[modem.Channel]*modem.Upstream{
+			"1": {Frequency: "38600000", Modulation: "QAM16"},
+		},
+	}
+
+	l := NewLog(4)
+	if events := l.Update(t0, prev); events != nil {
+		t.Errorf("First sample got events %+v", events)
+	}
+	got := l.Update(t1, cur)
+	want := []Event{
+		{Time: t1, Direction: Downstream, Channel: "2", Kind: FrequencyChanged, Old: "597000000", New: "609000000"},
+		{Time: t1, Direction: Downstream, Channel: "2", Kind: ModulationChanged, Old: "QAM256", New: "QAM64"},
+		{Time: t1, Direction: Downstream, Channel: "3", Kind: Removed, Old: "603000000"},
+		{Time: t1, Direction: Downstream, Channel: "10", Kind: Added, New: "651000000"},
+		{Time: t1, Direction: Upstream, Channel: "1", Kind: ModulationChanged, Old: "64QAM", New: "QAM16"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got:\n%+v\nWant:\n%+v", got, want)
+	}
+
+	// Only the most recent 4 are kept.
+	if got := l.Events(time.Time{}); !reflect.DeepEqual(got, want[1:]) {
+		t.Errorf("Events got:\n%+v\nWant:\n%+v", got, want[1:])
+	}
+	if got := l.Events(t1); len(got) != 0 {
+		t.Errorf("Events since last update got %+v", got)
+	}
+
+	w := httptest.NewRecorder()
+	l.ServeHTTP(w, httptest.NewRequest("GET", "/?since="+t0.Format(time.RFC3339), nil))
+	var served []Event
+	if err := json.Unmarshal(w.Body.Bytes(), &served); err != nil {
+		t.Fatalf("Failed to decode %q: %v", w.Body, err)
+	}
+	if !reflect.DeepEqual(served, want[1:]) {
+		t.Errorf("Served:\n%+v\nWant:\n%+v", served, want[1:])
+	}
+
+	w = httptest.NewRecorder()
+	l.ServeHTTP(w, httptest.NewRequest("GET", "/?since=yesterday", nil))
+	if w.Code != 400 {
+		t.Errorf("Invalid since got status %d want 400", w.Code)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modem_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/modem/fritzbox"
+	"github.com/wathiede/surfer/modem/hitron"
+	"github.com/wathiede/surfer/modem/netgear"
+	"github.com/wathiede/surfer/modem/sb6121"
+	"github.com/wathiede/surfer/modem/sb6183"
+	"github.com/wathiede/surfer/modem/sb8200"
+	"github.com/wathiede/surfer/modem/tc4400"
+)
+
+// TestHungModemDeadline checks every driver abandons a modem that never
+// responds once the context passed to Status expires.
+func TestHungModemDeadline(t *testing.T) {
+	client := &http.Client{Transport: slow(http.DefaultTransport, time.Hour)}
+	for _, m := range []modem.Modem{
+		fritzbox.New(),
+		hitron.New(),
+		netgear.New(),
+		sb6121.New(),
+		sb6183.New(),
+		sb8200.New(),
+		tc4400.New(),
+	} {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		start := time.Now()
+		done := make(chan error, 1)
+		go func() {
+			_, err := m.Status(ctx, client)
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			if err == nil {
+				t.Errorf("%s: Status succeeded against a hung modem", m.Name())
+			}
+			if d := time.Since(start); d > time.Second {
+				t.Errorf("%s: Status took %v to give up", m.Name(), d)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("%s: Status ignored the deadline", m.Name())
+		}
+		cancel()
+	}
+}
+
+type slowTransport struct {
+	rt http.RoundTripper
+	d  time.Duration
+}
+
+// slow returns an http.RoundTripper that waits d before passing each request
+// to rt, like a modem that's slow to respond, or hung if d is long.  The wait
+// ends early with the request context's error if it's done first.
+func slow(rt http.RoundTripper, d time.Duration) http.RoundTripper {
+	return &slowTransport{rt: rt, d: d}
+}
+
+func (s *slowTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t := time.NewTimer(s.d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return s.rt.RoundTrip(req)
+	case <-req.Context().Done():
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, req.Context().Err()
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package errorrate derives codeword error rates from the running totals
+// modems report, by comparing successive samples of a modem.Signal.
+package errorrate
+
+import (
+	"sync"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+)
+
+// Rate is the change in a downstream channel's codeword counters between two
+// samples.
+type Rate struct {
+	// Interval is the time between the samples.
+	Interval time.Duration `json:"interval"`
+	// Unerrored, Correctable and Uncorrectable are the number of codewords
+	// of each kind received in Interval.
+	Unerrored     float64 `json:"unerrored"`
+	Correctable   float64 `json:"correctable"`
+	Uncorrectable float64 `json:"uncorrectable"`
+	// Reset is true if a counter went backwards, e.g. the modem rebooted,
+	// in which case the current totals are taken as counted since the
+	// reset.
+	Reset bool `json:"reset,omitempty"`
+}
+
+// CorrectablePerSecond returns the rate of correctable codewords.
+func (r Rate) CorrectablePerSecond() float64 {
+	return r.Correctable / r.Interval.Seconds()
+}
+
+// UncorrectablePerSecond returns the rate of uncorrectable codewords.
+func (r Rate) UncorrectablePerSecond() float64 {
+	return r.Uncorrectable / r.Interval.Seconds()
+}
+
+// ErrorRatio returns the fraction of codewords received that were
+// uncorrectable.  ok is false if it can't be known, because the modem
+// doesn't report unerrored codewords or none were received.
+func (r Rate) ErrorRatio() (ratio float64, ok bool) {
+	total := r.Unerrored + r.Correctable + r.Uncorrectable
+	if r.Unerrored == 0 || total == 0 {
+		return 0, false
+	}
+	return r.Uncorrectable / total, true
+}
+
+type sample struct {
+	t time.Time
+	d modem.Downstream
+}
+
+// Tracker keeps the previous sample of each downstream channel.  It is safe
+// for concurrent use.
+type Tracker struct {
+	mu   sync.Mutex
+	prev map[modem.Channel]sample
+}
+
+// NewTracker returns a Tracker with no samples.
+func NewTracker() *Tracker {
+	return &Tracker{prev: map[modem.Channel]sample{}}
+}
+
+// delta returns cur-prev, or cur if the counter went backwards.
+func delta(cur, prev float64, reset *bool) float64 {
+	if cur < prev {
+		*reset = true
+		return cur
+	}
+	return cur - prev
+}
+
+// Update records s as sampled at t, and returns the Rate of each channel
+// also present in the previous sample.  Channels seen for the first time, or
+// sampled again without time passing, have no Rate.
+func (tr *Tracker) Update(t time.Time, s *modem.Signal) map[modem.Channel]Rate {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	rates := map[modem.Channel]Rate{}
+	for ch, d := range s.Downstream {
+		p, ok := tr.prev[ch]
+		tr.prev[ch] = sample{t: t, d: *d}
+		if !ok || !t.After(p.t) {
+			continue
+		}
+		r := Rate{Interval: t.Sub(p.t)}
+		r.Unerrored = delta(d.Unerrored, p.d.Unerrored, &r.Reset)
+		r.Correctable = delta(d.Correctable, p.d.Correctable, &r.Reset)
+		r.Uncorrectable = delta(d.Uncorrectable, p.d.Uncorrectable, &r.Reset)
+		if r.Reset {
+			r.Unerrored, r.Correctable, r.Uncorrectable = d.Unerrored, d.Correctable, d.Uncorrectable
+		}
+		rates[ch] = r
+	}
+	return rates
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errorrate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+)
+
+func signal(unerrored, correctable, uncorrectable float64) *modem.Signal {
+	return &modem.Signal{
+		Downstream: map[modem.Channel]*modem.Downstream{
+			"1": {Unerrored: unerrored, Correctable: correctable, Uncorrectable: uncorrectable},
+		},
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	tr := NewTracker()
+	t0 := time.Unix(1000, 0)
+	if rates := tr.Update(t0, signal(1000, 10, 1)); len(rates) != 0 {
+		t.Errorf("First sample got rates %v", rates)
+	}
+
+	rates := tr.Update(t0.Add(10*time.Second), signal(100940, 60, 11))
+	r, ok := rates["1"]
+	if !ok {
+		t.Fatalf("Second sample got no rate")
+	}
+	if want := (Rate{Interval: 10 * time.Second, Unerrored: 99940, Correctable: 50, Uncorrectable: 10}); r != want {
+		t.Errorf("Got %+v want %+v", r, want)
+	}
+	if got, want := r.CorrectablePerSecond(), 5.0; got != want {
+		t.Errorf("CorrectablePerSecond got %v want %v", got, want)
+	}
+	if got, want := r.UncorrectablePerSecond(), 1.0; got != want {
+		t.Errorf("UncorrectablePerSecond got %v want %v", got, want)
+	}
+	if got, ok := r.ErrorRatio(); !ok || got != 1e-4 {
+		t.Errorf("ErrorRatio got %v, %v want %v", got, ok, 1e-4)
+	}
+
+	// The modem rebooted, and counted these since.
+	r = tr.Update(t0.Add(20*time.Second), signal(500, 3, 0))["1"]
+	if want := (Rate{Interval: 10 * time.Second, Unerrored: 500, Correctable: 3, Reset: true}); r != want {
+		t.Errorf("After reset got %+v want %+v", r, want)
+	}
+
+	// Modems that don't report unerrored codewords have no ratio.
+	tr = NewTracker()
+	tr.Update(t0, signal(0, 10, 1))
+	r = tr.Update(t0.Add(time.Second), signal(0, 20, 2))["1"]
+	if _, ok := r.ErrorRatio(); ok {
+		t.Errorf("ErrorRatio known without unerrored codewords")
+	}
+	if got, want := r.UncorrectablePerSecond(), 1.0; got != want {
+		t.Errorf("UncorrectablePerSecond got %v want %v", got, want)
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modem
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// fakeTransport is an http.RoundTripper serving recorded pages keyed by URL
+// path.
+type fakeTransport struct {
+	// pages maps a URL path, e.g. "/cmconnectionstatus.html", to its
+	// contents.
+	pages map[string][]byte
+	// all, if not nil, is served for every URL.
+	all []byte
+}
+
+// NewFakeTransport returns an http.RoundTripper that serves pages from p
+// instead of the network, so Modem implementations read fake data through
+// the same code as real data.  Only the path of a request's URL is
+// considered.  p may be:
+//   - a single file, which is served for every URL.
+//   - a directory, where a URL path like /data/dsinfo.asp is served from
+//     data/dsinfo.asp under it, and / from index.html.  If the directory has
+//     a manifest written by Capture, the manifest's URLs are served from the
+//     files it lists.
+//   - a .zip archive laid out like a directory.
+//   - a directory holding only captures, i.e. directories or .zip archives
+//     with a manifest.  The captures are served in name order, advancing to
+//     the next each time a Modem returned by WithTransport reads its status,
+//     and starting over after the last.
+//
+// Requests for unknown paths get a 404 response.
+func NewFakeTransport(p string) (http.RoundTripper, error) {
+	fi, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		s, err := seriesTransport(p)
+		if err != nil {
+			return nil, err
+		}
+		if s != nil {
+			return s, nil
+		}
+		return dirTransport(p)
+	}
+	if strings.HasSuffix(p, ".zip") {
+		return zipTransport(p)
+	}
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeTransport{all: b}, nil
+}
+
+func dirTransport(dir string) (*fakeTransport, error) {
+	t := &fakeTransport{pages: map[string][]byte{}}
+	err := filepath.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		b, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		t.pages["/"+filepath.ToSlash(rel)] = b
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, t.applyManifest()
+}
+
+func zipTransport(p string) (*fakeTransport, error) {
+	zr, err := zip.OpenReader(p)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	t := &fakeTransport{pages: map[string][]byte{}}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		b, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		t.pages[path.Clean("/"+f.Name)] = b
+	}
+	return t, t.applyManifest()
+}
+
+// seriesTransport returns a fakeSeries of the captures in dir, or nil if dir
+// holds anything other than captures.
+func seriesTransport(dir string) (*fakeSeries, error) {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, fi := range fis {
+		name := filepath.Join(dir, fi.Name())
+		switch {
+		case fi.IsDir():
+			if _, err := os.Stat(filepath.Join(name, ManifestFile)); err != nil {
+				return nil, nil
+			}
+		case strings.HasSuffix(name, ".zip"):
+		default:
+			return nil, nil
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	sort.Strings(names)
+	s := &fakeSeries{}
+	for _, name := range names {
+		var t *fakeTransport
+		if strings.HasSuffix(name, ".zip") {
+			t, err = zipTransport(name)
+		} else {
+			t, err = dirTransport(name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := t.pages["/"+ManifestFile]; !ok {
+			return nil, nil
+		}
+		s.captures = append(s.captures, t)
+	}
+	return s, nil
+}
+
+// applyManifest maps the URLs in a Capture manifest, if present, to the
+// files recorded for them.
+func (t *fakeTransport) applyManifest() error {
+	b, ok := t.pages["/"+ManifestFile]
+	if !ok {
+		return nil
+	}
+	var man Manifest
+	if err := json.Unmarshal(b, &man); err != nil {
+		return fmt.Errorf("Failed to parse %s: %v", ManifestFile, err)
+	}
+	for _, p := range man.Pages {
+		if p.File == "" {
+			continue
+		}
+		u, err := url.Parse(p.URL)
+		if err != nil {
+			return err
+		}
+		page, ok := t.pages["/"+filepath.ToSlash(p.File)]
+		if !ok {
+			return fmt.Errorf("%s lists missing file %q", ManifestFile, p.File)
+		}
+		k := path.Clean("/" + u.Path)
+		if k == "/" {
+			// RoundTrip serves / from index.html.
+			k = "/index.html"
+		}
+		t.pages[k] = page
+	}
+	return nil
+}
+
+func (t *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	b := t.all
+	if b == nil {
+		p := path.Clean("/" + req.URL.Path)
+		if p == "/" {
+			p = "/index.html"
+		}
+		b = t.pages[p]
+	}
+	code := http.StatusOK
+	if b == nil {
+		code = http.StatusNotFound
+		b = []byte("404 page not found\n")
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", code, http.StatusText(code)),
+		StatusCode:    code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{},
+		Body:          ioutil.NopCloser(bytes.NewReader(b)),
+		ContentLength: int64(len(b)),
+		Request:       req,
+	}, nil
+}
+
+// fakeSeries is an http.RoundTripper serving one of several captures at a
+// time.
+type fakeSeries struct {
+	mu       sync.Mutex
+	captures []*fakeTransport
+	next     int
+}
+
+func (s *fakeSeries) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	t := s.captures[s.next]
+	s.mu.Unlock()
+	return t.RoundTrip(req)
+}
+
+// step advances to the next capture, wrapping around after the last.
+func (s *fakeSeries) step() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next = (s.next + 1) % len(s.captures)
+}
+
+// transportModem is a Modem whose requests always go through transport.
+type transportModem struct {
+	Modem
+	transport http.RoundTripper
+}
+
+// WithTransport returns a Modem that makes requests for m through t, rather
+// than the Transport of the http.Client passed to Status.  It is used to
+// bind a Modem to fake data.  If t serves a series of captures, each call to
+// Status reads the next one.
+func WithTransport(m Modem, t http.RoundTripper) Modem {
+	return &transportModem{Modem: m, transport: t}
+}
+
+func (tm *transportModem) Status(ctx context.Context, client *http.Client) (*Signal, error) {
+	c := *client
+	c.Transport = tm.transport
+	client = &c
+	if s, ok := tm.transport.(*fakeSeries); ok {
+		defer s.step()
+	}
+	return tm.Modem.Status(ctx, client)
+}
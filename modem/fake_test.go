@@ -0,0 +1,182 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modem
+
+import (
+	"archive/zip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, p, s string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(p, []byte(s), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// checkPages fetches each URL in want through rt and compares the body, or
+// expects a 404 if the wanted body is empty.
+func checkPages(t *testing.T, name string, rt http.RoundTripper, want map[string]string) {
+	t.Helper()
+	client := &http.Client{Transport: rt}
+	for u, w := range want {
+		resp, err := client.Get(u)
+		if err != nil {
+			t.Errorf("%s: GET %s: %v", name, u, err)
+			continue
+		}
+		b, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Errorf("%s: GET %s: %v", name, u, err)
+			continue
+		}
+		if w == "" {
+			if resp.StatusCode != http.StatusNotFound {
+				t.Errorf("%s: GET %s got status %d want 404", name, u, resp.StatusCode)
+			}
+			continue
+		}
+		if got := string(b); got != w {
+			t.Errorf("%s: GET %s got %q want %q", name, u, got, w)
+		}
+	}
+}
+
+func TestNewFakeTransport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "status.html")
+	writeFile(t, file, "status")
+	rt, err := NewFakeTransport(file)
+	if err != nil {
+		t.Fatalf("NewFakeTransport(%q): %v", file, err)
+	}
+	checkPages(t, "file", rt, map[string]string{
+		"http://192.168.100.1/":             "status",
+		"http://192.168.100.1/any/page.asp": "status",
+	})
+
+	pages := filepath.Join(dir, "pages")
+	writeFile(t, filepath.Join(pages, "index.html"), "index")
+	writeFile(t, filepath.Join(pages, "data", "dsinfo.asp"), "ds")
+	writeFile(t, filepath.Join(pages, "signal.html"), "signal")
+	writeFile(t, filepath.Join(pages, ManifestFile), `{"pages": [{"url": "http://192.168.100.1/cgi-bin/status", "file": "signal.html"}, {"url": "http://192.168.100.1/broken.html", "error": "GET: 500"}]}`)
+	want := map[string]string{
+		"http://192.168.100.1/":                "index",
+		"http://192.168.100.1/data/dsinfo.asp": "ds",
+		"http://192.168.100.1/cgi-bin/status":  "signal",
+		"http://192.168.100.1/broken.html":     "",
+		"http://192.168.100.1/missing.html":    "",
+	}
+	rt, err = NewFakeTransport(pages)
+	if err != nil {
+		t.Fatalf("NewFakeTransport(%q): %v", pages, err)
+	}
+	checkPages(t, "directory", rt, want)
+
+	zp := filepath.Join(dir, "pages.zip")
+	f, err := os.Create(zp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, name := range []string{"index.html", "data/dsinfo.asp", "signal.html", ManifestFile} {
+		b, err := ioutil.ReadFile(filepath.Join(pages, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(b)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	rt, err = NewFakeTransport(zp)
+	if err != nil {
+		t.Fatalf("NewFakeTransport(%q): %v", zp, err)
+	}
+	checkPages(t, "zip", rt, want)
+
+	if _, err := NewFakeTransport(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("Expected error for missing path")
+	}
+	root := filepath.Join(dir, "root")
+	writeFile(t, filepath.Join(root, "status.html"), "status")
+	writeFile(t, filepath.Join(root, ManifestFile), `{"pages": [{"url": "http://192.168.100.1/", "file": "status.html"}]}`)
+	rt, err = NewFakeTransport(root)
+	if err != nil {
+		t.Fatalf("NewFakeTransport(%q): %v", root, err)
+	}
+	checkPages(t, "manifest with /", rt, map[string]string{
+		"http://192.168.100.1/": "status",
+	})
+
+	writeFile(t, filepath.Join(pages, ManifestFile), `{"pages": [{"url": "http://192.168.100.1/", "file": "gone.html"}]}`)
+	if _, err := NewFakeTransport(pages); err == nil {
+		t.Errorf("Expected error for manifest listing a missing file")
+	}
+}
+
+func TestFakeSeries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "series")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"2", "1", "3"} {
+		writeFile(t, filepath.Join(dir, name, "index.html"), "capture "+name)
+		writeFile(t, filepath.Join(dir, name, ManifestFile), `{"pages": [{"url": "http://192.168.100.1/", "file": "index.html"}]}`)
+	}
+	rt, err := NewFakeTransport(dir)
+	if err != nil {
+		t.Fatalf("NewFakeTransport(%q): %v", dir, err)
+	}
+	m := WithTransport(pagerModem{}, rt)
+	for _, want := range []string{"capture 1", "capture 2", "capture 3", "capture 1"} {
+		checkPages(t, "series", rt, map[string]string{"http://192.168.100.1/": want})
+		if _, err := m.Status(context.Background(), &http.Client{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A directory that also holds other files is a single capture.
+	writeFile(t, filepath.Join(dir, "index.html"), "top")
+	rt, err = NewFakeTransport(dir)
+	if err != nil {
+		t.Fatalf("NewFakeTransport(%q): %v", dir, err)
+	}
+	checkPages(t, "directory", rt, map[string]string{
+		"http://192.168.100.1/":             "top",
+		"http://192.168.100.1/2/index.html": "capture 2",
+	})
+}
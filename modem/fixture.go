@@ -0,0 +1,169 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modem
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// ManifestFile is the name of the manifest written by Capture in the fixture
+// directory.
+const ManifestFile = "manifest.json"
+
+// Pager is implemented by Modems that can capture every page they read,
+// e.g. the status page, event log and product information.
+type Pager interface {
+	// Pages returns the URLs of the pages.  The first is the one Status
+	// parses.
+	Pages() []string
+	// Fetch gets the page at u, one of Pages, the way Status would, e.g.
+	// logging in or sending credentials first.
+	Fetch(ctx context.Context, client *http.Client, u string) ([]byte, error)
+}
+
+// CapturedPage records a single page saved by Capture.
+type CapturedPage struct {
+	URL string `json:"url"`
+	// File is relative to the fixture directory.
+	File string `json:"file,omitempty"`
+	// Error is set if the page could not be fetched.
+	Error string `json:"error,omitempty"`
+}
+
+// Manifest describes a fixture directory written by Capture.
+type Manifest struct {
+	Modem    string         `json:"modem"`
+	Captured time.Time      `json:"captured"`
+	Pages    []CapturedPage `json:"pages"`
+}
+
+// ReadManifest reads the manifest from the fixture directory dir.
+func ReadManifest(dir string) (*Manifest, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("Failed to parse manifest in %q: %v", dir, err)
+	}
+	return m, nil
+}
+
+// fixtureFile maps a page URL to a file name relative to the fixture
+// directory, mirroring the URL path.
+func fixtureFile(u string) (string, error) {
+	pu, err := url.Parse(u)
+	if err != nil {
+		return "", err
+	}
+	p := path.Clean("/" + pu.Path)
+	if p == "/" {
+		p = "/index.html"
+	}
+	return filepath.FromSlash(p[1:]), nil
+}
+
+// Capture fetches every page of m and writes them into dir along with a
+// Manifest, so they can later be used as fake data.  If transform is not nil,
+// it is applied to each page before it is written, e.g. to redact
+// identifiers.  Each page, including any login its fetch needs, gets timeout
+// to be fetched in, or ctx's deadline alone if timeout is zero.  Pages that
+// fail to fetch are noted in the manifest, but it is an error if the first,
+// status, page fails.
+func Capture(ctx context.Context, client *http.Client, m Modem, dir string, timeout time.Duration, transform func([]byte) []byte) (*Manifest, error) {
+	p, ok := m.(Pager)
+	if !ok {
+		return nil, fmt.Errorf("%s does not support capturing pages", m.Name())
+	}
+	man := &Manifest{
+		Modem:    m.Name(),
+		Captured: time.Now().UTC(),
+	}
+	for i, u := range p.Pages() {
+		cp := CapturedPage{URL: u}
+		b, err := fetch(ctx, client, p, u, timeout)
+		if err == nil {
+			if transform != nil {
+				b = transform(b)
+			}
+			cp.File, err = fixtureFile(u)
+		}
+		if err == nil {
+			f := filepath.Join(dir, cp.File)
+			if err = os.MkdirAll(filepath.Dir(f), 0755); err == nil {
+				err = ioutil.WriteFile(f, b, 0644)
+			}
+		}
+		if err != nil {
+			if i == 0 {
+				return nil, fmt.Errorf("Failed to capture status page %q: %v", u, err)
+			}
+			cp.File = ""
+			cp.Error = err.Error()
+		}
+		man.Pages = append(man.Pages, cp)
+	}
+	b, err := json.MarshalIndent(man, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, ManifestFile), append(b, '\n'), 0644); err != nil {
+		return nil, err
+	}
+	return man, nil
+}
+
+// fetch fetches u from p, giving up after timeout if it's positive.
+func fetch(ctx context.Context, client *http.Client, p Pager, u string, timeout time.Duration) ([]byte, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return p.Fetch(ctx, client, u)
+}
+
+// GetPage gets u, sending c as HTTP basic auth if it isn't nil.  It's for
+// Pager.Fetch implementations that need nothing more than a GET.
+func GetPage(ctx context.Context, client *http.Client, u string, c *Credentials) ([]byte, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c != nil {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	return ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modem
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type pagerModem struct {
+	pages []string
+}
+
+func (pagerModem) Name() string { return "Pager" }
+
+func (pagerModem) Status(context.Context, *http.Client) (*Signal, error) { return &Signal{}, nil }
+
+func (p pagerModem) Pages() []string { return p.pages }
+
+func (pagerModem) Fetch(ctx context.Context, client *http.Client, u string) ([]byte, error) {
+	return GetPage(ctx, client, u, nil)
+}
+
+func TestCapture(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.html" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, "page %s", r.URL.Path)
+	}))
+	defer s.Close()
+
+	dir, err := ioutil.TempDir("", "capture")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := pagerModem{pages: []string{s.URL + "/", s.URL + "/data/info.asp", s.URL + "/missing.html"}}
+	upper := func(b []byte) []byte { return bytes.ToUpper(b) }
+	if _, err := Capture(context.Background(), s.Client(), m, dir, 0, upper); err != nil {
+		t.Fatalf("Capture failed: %v", err)
+	}
+
+	man, err := ReadManifest(dir)
+	if err != nil {
+		t.Fatalf("ReadManifest failed: %v", err)
+	}
+	if man.Modem != "Pager" {
+		t.Errorf("Manifest modem got %q want %q", man.Modem, "Pager")
+	}
+	var files []string
+	for _, p := range man.Pages {
+		files = append(files, p.File)
+	}
+	if want := []string{"index.html", filepath.Join("data", "info.asp"), ""}; !reflect.DeepEqual(files, want) {
+		t.Errorf("Manifest files got %q want %q", files, want)
+	}
+	if man.Pages[2].Error == "" {
+		t.Errorf("Expected error recorded for missing page")
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, "data", "info.asp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), "PAGE /DATA/INFO.ASP"; got != want {
+		t.Errorf("Captured page got %q want %q", got, want)
+	}
+
+	m.pages = []string{s.URL + "/missing.html"}
+	if _, err := Capture(context.Background(), s.Client(), m, dir, 0, nil); err == nil {
+		t.Errorf("Expected error when status page is missing")
+	}
+}
+
+func TestCapturePageTimeout(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		fmt.Fprintf(w, "page %s", r.URL.Path)
+	}))
+	defer s.Close()
+
+	dir, err := ioutil.TempDir("", "capture")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Together the pages take longer than the timeout, but each fits in it.
+	m := pagerModem{pages: []string{s.URL + "/", s.URL + "/a.html", s.URL + "/b.html", s.URL + "/c.html"}}
+	man, err := Capture(context.Background(), s.Client(), m, dir, 300*time.Millisecond, nil)
+	if err != nil {
+		t.Fatalf("Capture failed: %v", err)
+	}
+	for _, p := range man.Pages {
+		if p.Error != "" {
+			t.Errorf("Page %s got error %q", p.URL, p.Error)
+		}
+	}
+}
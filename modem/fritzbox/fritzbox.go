@@ -0,0 +1,330 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fritzbox scrapes DOCSIS channel status from AVM FRITZ!Box Cable
+// routers (6490, 6591, 6660).  The router requires a session ID obtained
+// through a challenge/response login, after which data.lua serves the
+// "docInfo" page as JSON.
+package fritzbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golang/glog"
+
+	"github.com/wathiede/surfer/modem"
+)
+
+const defaultURL = "http://192.168.178.1"
+
+// value holds a JSON string or number.  Different FRITZ!OS versions encode
+// the same docInfo fields as either.
+type value string
+
+func (v *value) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*v = value(s)
+		return nil
+	}
+	*v = value(b)
+	return nil
+}
+
+// number parses the leading number of v, e.g. 751 from "751 - 860".
+func (v value) number() float64 {
+	fs := strings.Fields(string(v))
+	if len(fs) == 0 {
+		return 0
+	}
+	f, _ := strconv.ParseFloat(fs[0], 64)
+	return f
+}
+
+// hz converts a frequency reported in MHz to a string in Hz.
+func (v value) hz() string {
+	return strconv.FormatFloat(math.Round(v.number()*1e6), 'f', -1, 64)
+}
+
+type channel struct {
+	ChannelID     value `json:"channelID"`
+	Type          value `json:"type"`
+	Frequency     value `json:"frequency"`
+	PowerLevel    value `json:"powerLevel"`
+	MSE           value `json:"mse"`
+	MER           value `json:"mer"`
+	CorrErrors    value `json:"corrErrors"`
+	NonCorrErrors value `json:"nonCorrErrors"`
+}
+
+type channels struct {
+	Docsis30 []channel `json:"docsis30"`
+	Docsis31 []channel `json:"docsis31"`
+}
+
+// docInfo is the response to data.lua?page=docInfo.
+type docInfo struct {
+	Data struct {
+		ChannelDs channels `json:"channelDs"`
+		ChannelUs channels `json:"channelUs"`
+	} `json:"data"`
+}
+
+type fritzbox struct {
+	baseURL string
+	// noLogin is set for fake data serving docInfo for every URL, which
+	// has no login page.
+	noLogin bool
+
+	mu  sync.Mutex
+	sid string
+}
+
+func (*fritzbox) Name() string { return "FRITZ!Box Cable" }
+
+func isFritzBox(b []byte) bool {
+	return bytes.Contains(b, []byte("<SessionInfo>"))
+}
+
+func isDocInfo(b []byte) bool {
+	return bytes.Contains(b, []byte(`"channelDs"`))
+}
+
+func probe(ctx context.Context, client *http.Client) modem.Modem {
+	u := defaultURL + "/login_sid.lua?version=2"
+	glog.Infof("Probing %q", u)
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		glog.Errorf("Failed to create request: %v", err)
+		return nil
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		glog.Errorf("Failed to get login page: %v", err)
+		return nil
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		glog.Errorf("Failed to read login page: %v", err)
+		return nil
+	}
+	switch {
+	case isFritzBox(b):
+		return New()
+	case isDocInfo(b):
+		// Only a single docInfo file passed to -fake, served for
+		// every URL, answers the login page with docInfo.
+		return &fritzbox{baseURL: defaultURL, noLogin: true}
+	}
+	return nil
+}
+
+func init() {
+	modem.Register(probe)
+}
+
+// New returns a modem.Modem that logs in to a FRITZ!Box at the default
+// address.  The password, and optionally the user name, are taken from
+// modem.Credentials in the context passed to Status.
+func New() modem.Modem {
+	return NewURL(defaultURL)
+}
+
+// NewURL is like New, but talks to the FRITZ!Box at baseURL, e.g.
+// "http://fritz.box".
+func NewURL(baseURL string) modem.Modem {
+	return &fritzbox{baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// NewFakeData returns a modem.Modem that will log in to and parse docInfo
+// JSON from the fake data at path, see modem.NewFakeTransport.  path is
+// either a single docInfo JSON file, or serves both login_sid.lua and
+// data.lua, e.g. a directory with a manifest mapping them to recorded pages.
+func NewFakeData(path string) (modem.Modem, error) {
+	t, err := modem.NewFakeTransport(path)
+	if err != nil {
+		return nil, err
+	}
+	m := probe(context.Background(), &http.Client{Transport: t})
+	if m == nil {
+		return nil, fmt.Errorf("%q does not contain FRITZ!Box data", path)
+	}
+	return modem.WithTransport(m, t), nil
+}
+
+// Status will return signal data parsed from the docInfo JSON.  A session is
+// established, if one isn't already, and data.lua is queried.  Without
+// modem.Credentials in ctx, an empty password is tried.
+func (fb *fritzbox) Status(ctx context.Context, client *http.Client) (*modem.Signal, error) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+	b, err := fb.docInfo(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	return parseDocInfo(b)
+}
+
+// Pages returns the URLs of the docInfo data and the login page, which
+// together can be replayed as fake data.
+func (fb *fritzbox) Pages() []string {
+	return []string{fb.baseURL + "/data.lua", fb.baseURL + "/login_sid.lua"}
+}
+
+// Fetch gets u, one of Pages, after logging in like Status.  The login page
+// is fetched with the session, so it carries a valid session ID when
+// replayed.
+func (fb *fritzbox) Fetch(ctx context.Context, client *http.Client, u string) ([]byte, error) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+	b, err := fb.docInfo(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	switch u {
+	case fb.baseURL + "/data.lua":
+		return b, nil
+	case fb.baseURL + "/login_sid.lua":
+		return modem.GetPage(ctx, client, u+"?version=2&sid="+url.QueryEscape(fb.sid), nil)
+	}
+	return nil, fmt.Errorf("unknown page %q", u)
+}
+
+// docInfo returns the docInfo JSON, logging in first if there's no session
+// or it expired.  fb.mu must be held.
+func (fb *fritzbox) docInfo(ctx context.Context, client *http.Client) ([]byte, error) {
+	if fb.noLogin {
+		return fb.getDocInfo(ctx, client)
+	}
+	if fb.sid != "" {
+		b, err := fb.getDocInfo(ctx, client)
+		if err == nil {
+			return b, nil
+		}
+		// The session may have expired, log in again below.
+		glog.V(1).Infof("Failed to get docInfo with existing session: %v", err)
+		fb.sid = ""
+	}
+	c, _ := modem.CredentialsFromContext(ctx)
+	sid, err := login(ctx, client, fb.baseURL, c.Username, c.Password)
+	if err != nil {
+		return nil, err
+	}
+	fb.sid = sid
+	return fb.getDocInfo(ctx, client)
+}
+
+func (fb *fritzbox) getDocInfo(ctx context.Context, client *http.Client) ([]byte, error) {
+	form := url.Values{
+		"xhr":         {"1"},
+		"sid":         {fb.sid},
+		"lang":        {"en"},
+		"page":        {"docInfo"},
+		"xhrId":       {"all"},
+		"no_sidrenew": {""},
+	}
+	req, err := http.NewRequest("POST", fb.baseURL+"/data.lua", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("POST data.lua: %s", resp.Status)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	// An invalid session gets the HTML login page rather than JSON.
+	if !isDocInfo(b) {
+		return nil, fmt.Errorf("data.lua returned no docInfo")
+	}
+	return b, nil
+}
+
+func parseDocInfo(b []byte) (*modem.Signal, error) {
+	var di docInfo
+	if err := json.Unmarshal(b, &di); err != nil {
+		return nil, err
+	}
+	s := &modem.Signal{
+		Downstream: map[modem.Channel]*modem.Downstream{},
+		Upstream:   map[modem.Channel]*modem.Upstream{},
+	}
+	for _, c := range di.Data.ChannelDs.Docsis30 {
+		d := downstream(c)
+		d.Modulation = "QAM" + strings.TrimSuffix(string(c.Type), "QAM")
+		s.Downstream[modem.Channel(c.ChannelID)] = d
+	}
+	// DOCSIS 3.1 channels are numbered independently of 3.0 channels, so
+	// their IDs are prefixed to keep them apart, as the Netgear driver
+	// does.
+	for _, c := range di.Data.ChannelDs.Docsis31 {
+		d := downstream(c)
+		d.Modulation = "OFDM"
+		s.Downstream[modem.Channel("OFDM-"+c.ChannelID)] = d
+	}
+	for _, c := range di.Data.ChannelUs.Docsis30 {
+		s.Upstream[modem.Channel(c.ChannelID)] = &modem.Upstream{
+			Frequency:  c.Frequency.hz(),
+			PowerLevel: c.PowerLevel.number(),
+			Modulation: "QAM" + strings.TrimSuffix(string(c.Type), "QAM"),
+		}
+	}
+	for _, c := range di.Data.ChannelUs.Docsis31 {
+		s.Upstream[modem.Channel("OFDMA-"+c.ChannelID)] = &modem.Upstream{
+			Frequency:  c.Frequency.hz(),
+			PowerLevel: c.PowerLevel.number(),
+			Modulation: "OFDMA",
+		}
+	}
+	return s, nil
+}
+
+func downstream(c channel) *modem.Downstream {
+	d := &modem.Downstream{
+		Frequency:     c.Frequency.hz(),
+		PowerLevel:    c.PowerLevel.number(),
+		Correctable:   c.CorrErrors.number(),
+		Uncorrectable: c.NonCorrErrors.number(),
+	}
+	// DOCSIS 3.0 channels report MSE, which is the negated SNR.  DOCSIS 3.1
+	// channels, and some firmware for 3.0 channels, report MER instead.
+	if c.MER != "" {
+		d.SNR = c.MER.number()
+	} else {
+		d.SNR = -c.MSE.number()
+	}
+	return d
+}
@@ -0,0 +1 @@
+<?xml version="1.0" encoding="utf-8"?><SessionInfo><SID>0123456789abcdef</SID><Challenge>1234567z</Challenge><BlockTime>0</BlockTime><Rights></Rights><Users><User last="1">fritz1234</User></Users></SessionInfo>
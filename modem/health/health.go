@@ -0,0 +1,283 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package health grades the channels of a modem.Signal against the power and
+// SNR ranges recommended for DOCSIS 3.0 and 3.1 cable modems.
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/wathiede/surfer/modem"
+)
+
+// Grade rates a channel, or a whole modem.  Higher is worse.
+type Grade int
+
+const (
+	// Good is within the recommended range.
+	Good Grade = iota
+	// Marginal works, but is close to causing errors or dropouts.
+	Marginal
+	// Bad is outside the range the modem can be expected to work in.
+	Bad
+)
+
+var gradeNames = []string{"good", "marginal", "bad"}
+
+func (g Grade) String() string {
+	if g < 0 || int(g) >= len(gradeNames) {
+		return fmt.Sprintf("Grade(%d)", int(g))
+	}
+	return gradeNames[g]
+}
+
+// MarshalText encodes g as its name, e.g. "marginal".
+func (g Grade) MarshalText() ([]byte, error) {
+	return []byte(g.String()), nil
+}
+
+// Range is a closed interval.
+type Range struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+func (r Range) contains(v float64) bool { return r.Min <= v && v <= r.Max }
+
+// Limits grade a value Good within Good, Marginal within Marginal, and Bad
+// otherwise.
+type Limits struct {
+	Good     Range `json:"good"`
+	Marginal Range `json:"marginal"`
+}
+
+// Grade returns the grade of v.
+func (l Limits) Grade(v float64) Grade {
+	switch {
+	case l.Good.contains(v):
+		return Good
+	case l.Marginal.contains(v):
+		return Marginal
+	}
+	return Bad
+}
+
+// Floor grades a value Good at or above Good, Marginal at or above Marginal,
+// and Bad otherwise.
+type Floor struct {
+	Good     float64 `json:"good"`
+	Marginal float64 `json:"marginal"`
+}
+
+// Grade returns the grade of v.
+func (f Floor) Grade(v float64) Grade {
+	switch {
+	case v >= f.Good:
+		return Good
+	case v >= f.Marginal:
+		return Marginal
+	}
+	return Bad
+}
+
+// Thresholds configure grading.
+type Thresholds struct {
+	// DownstreamPower limits downstream receive power in dBmV.
+	DownstreamPower Limits `json:"downstream_power"`
+	// DownstreamSNR holds minimum SNR, or MER for OFDM channels, in dB
+	// keyed by modulation, e.g. "QAM64", "QAM256" or "OFDM".
+	// Modulations not listed use DefaultSNR.
+	DownstreamSNR map[string]Floor `json:"downstream_snr"`
+	DefaultSNR    Floor            `json:"default_snr"`
+	// UpstreamPower limits upstream transmit power in dBmV keyed by the
+	// number of upstream channels, as each added channel lowers the power
+	// a modem can transmit on each.  Channel counts not listed use the
+	// limits of the largest count below them.
+	UpstreamPower map[int]Limits `json:"upstream_power"`
+}
+
+// DOCSIS returns the commonly recommended thresholds for DOCSIS 3.0 and 3.1
+// modems.
+func DOCSIS() Thresholds {
+	return Thresholds{
+		DownstreamPower: Limits{
+			Good:     Range{-7, 7},
+			Marginal: Range{-10, 10},
+		},
+		DownstreamSNR: map[string]Floor{
+			"QAM64":  {Good: 27, Marginal: 24},
+			"QAM256": {Good: 33, Marginal: 30},
+			"OFDM":   {Good: 38, Marginal: 34},
+		},
+		DefaultSNR: Floor{Good: 33, Marginal: 30},
+		UpstreamPower: map[int]Limits{
+			1: {Good: Range{35, 58}, Marginal: Range{30, 61}},
+			2: {Good: Range{35, 54}, Marginal: Range{30, 57}},
+			3: {Good: Range{35, 51}, Marginal: Range{30, 54}},
+			// Modems bonding more than 4 upstream channels,
+			// e.g. DOCSIS 3.1 with 8, are graded against
+			// these too, as there's no common recommendation
+			// beyond 4.
+			4: {Good: Range{35, 51}, Marginal: Range{30, 54}},
+		},
+	}
+}
+
+// ReadThresholds reads JSON encoded Thresholds from path.  Fields missing
+// from the file keep their DOCSIS values, including fields of map entries.
+// New map entries start from the values they would otherwise use: DefaultSNR
+// for a modulation, or the limits of the largest channel count below for an
+// upstream channel count.
+func ReadThresholds(path string) (Thresholds, error) {
+	t := DOCSIS()
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return t, err
+	}
+	// Struct fields are decoded over the defaults, but map entries would
+	// be replaced whole, so they're decoded separately.
+	var maps struct {
+		DownstreamSNR map[string]json.RawMessage `json:"downstream_snr"`
+		UpstreamPower map[int]json.RawMessage    `json:"upstream_power"`
+	}
+	if err := json.Unmarshal(b, &maps); err != nil {
+		return t, fmt.Errorf("Failed to parse %s: %v", path, err)
+	}
+	snr, up := t.DownstreamSNR, t.UpstreamPower
+	t.DownstreamSNR, t.UpstreamPower = nil, nil
+	if err := json.Unmarshal(b, &t); err != nil {
+		return t, fmt.Errorf("Failed to parse %s: %v", path, err)
+	}
+	t.DownstreamSNR, t.UpstreamPower = snr, up
+	for k, raw := range maps.DownstreamSNR {
+		f := t.snr(k)
+		if err := json.Unmarshal(raw, &f); err != nil {
+			return t, fmt.Errorf("Failed to parse %s: downstream_snr %s: %v", path, k, err)
+		}
+		t.DownstreamSNR[modulation(k)] = f
+	}
+	// Counts are applied in order, so a new count starts from any count
+	// below it in the file.
+	var counts []int
+	for n := range maps.UpstreamPower {
+		counts = append(counts, n)
+	}
+	sort.Ints(counts)
+	for _, n := range counts {
+		l, _ := t.upstreamPower(n)
+		if err := json.Unmarshal(maps.UpstreamPower[n], &l); err != nil {
+			return t, fmt.Errorf("Failed to parse %s: upstream_power %d: %v", path, n, err)
+		}
+		t.UpstreamPower[n] = l
+	}
+	return t, nil
+}
+
+// ChannelReport is the grade of a single channel.
+type ChannelReport struct {
+	Grade Grade `json:"grade"`
+	// Reasons explains a grade worse than Good.
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+func (c *ChannelReport) add(g Grade, format string, args ...interface{}) {
+	if g == Good {
+		return
+	}
+	if g > c.Grade {
+		c.Grade = g
+	}
+	c.Reasons = append(c.Reasons, fmt.Sprintf("%s: ", g)+fmt.Sprintf(format, args...))
+}
+
+// Report holds the grades of every channel of a modem.Signal.
+type Report struct {
+	// Overall is the worst grade of any channel.
+	Overall    Grade                            `json:"overall"`
+	Downstream map[modem.Channel]*ChannelReport `json:"downstream"`
+	Upstream   map[modem.Channel]*ChannelReport `json:"upstream"`
+}
+
+var qamRE = regexp.MustCompile(`(\d+)\s*-?QAM|QAM\s*-?(\d+)`)
+
+// modulation normalizes the modulation names reported by different modems,
+// e.g. "256QAM" and "QAM256", to the keys of Thresholds.DownstreamSNR.
+func modulation(m string) string {
+	m = strings.ToUpper(m)
+	if strings.Contains(m, "OFDM") {
+		return "OFDM"
+	}
+	if sm := qamRE.FindStringSubmatch(m); sm != nil {
+		return "QAM" + sm[1] + sm[2]
+	}
+	return m
+}
+
+func (t Thresholds) snr(mod string) Floor {
+	if f, ok := t.DownstreamSNR[modulation(mod)]; ok {
+		return f
+	}
+	return t.DefaultSNR
+}
+
+func (t Thresholds) upstreamPower(n int) (Limits, bool) {
+	best := 0
+	for k := range t.UpstreamPower {
+		if k <= n && k > best {
+			best = k
+		}
+	}
+	l, ok := t.UpstreamPower[best]
+	return l, ok
+}
+
+// Evaluate grades every channel in s.
+func (t Thresholds) Evaluate(s *modem.Signal) *Report {
+	r := &Report{
+		Downstream: map[modem.Channel]*ChannelReport{},
+		Upstream:   map[modem.Channel]*ChannelReport{},
+	}
+	for ch, d := range s.Downstream {
+		c := &ChannelReport{}
+		if strings.Contains(strings.ToLower(d.Status), "not locked") {
+			c.add(Bad, "not locked")
+		}
+		p := t.DownstreamPower
+		c.add(p.Grade(d.PowerLevel), "power %.1f dBmV outside %g to %g", d.PowerLevel, p.Good.Min, p.Good.Max)
+		f := t.snr(d.Modulation)
+		c.add(f.Grade(d.SNR), "SNR %.1f dB below %g", d.SNR, f.Good)
+		r.Downstream[ch] = c
+		if c.Grade > r.Overall {
+			r.Overall = c.Grade
+		}
+	}
+	l, ok := t.upstreamPower(len(s.Upstream))
+	for ch, u := range s.Upstream {
+		c := &ChannelReport{}
+		if ok {
+			c.add(l.Grade(u.PowerLevel), "power %.1f dBmV outside %g to %g for %d channels", u.PowerLevel, l.Good.Min, l.Good.Max, len(s.Upstream))
+		}
+		r.Upstream[ch] = c
+		if c.Grade > r.Overall {
+			r.Overall = c.Grade
+		}
+	}
+	return r
+}
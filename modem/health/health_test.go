@@ -0,0 +1,144 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/wathiede/surfer/modem"
+)
+
+func TestModulation(t *testing.T) {
+	for in, want := range map[string]string{
+		"QAM256":     "QAM256",
+		"256QAM":     "QAM256",
+		"qam 64":     "QAM64",
+		"OFDM PLC":   "OFDM",
+		"Other":      "OTHER",
+		"[3] 64QAM ": "QAM64",
+	} {
+		if got := modulation(in); got != want {
+			t.Errorf("modulation(%q) got %q want %q", in, got, want)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	s := &modem.Signal{
+		Downstream: map[modem.Channel]*modem.Downstream{
+			"1": {Modulation: "QAM256", PowerLevel: 3.2, SNR: 38.6},
+			"2": {Modulation: "QAM256", PowerLevel: -8, SNR: 38.6},
+			"3": {Modulation: "256QAM", PowerLevel: 0, SNR: 29.5},
+			"4": {Modulation: "QAM64", PowerLevel: 0, SNR: 29.5},
+			"5": {Modulation: "OFDM PLC", PowerLevel: 1, SNR: 36},
+			"6": {Modulation: "QAM256", PowerLevel: 0, SNR: 0, Status: "Not Locked"},
+		},
+		Upstream: map[modem.Channel]*modem.Upstream{
+			"1": {PowerLevel: 52},
+			"2": {PowerLevel: 49.5},
+			"3": {PowerLevel: 47},
+			"4": {PowerLevel: 33},
+		},
+	}
+	r := DOCSIS().Evaluate(s)
+	for ch, want := range map[modem.Channel]Grade{
+		"1": Good,
+		"2": Marginal,
+		"3": Bad,
+		"4": Good,
+		"5": Marginal,
+		"6": Bad,
+	} {
+		if got := r.Downstream[ch].Grade; got != want {
+			t.Errorf("Downstream %s got %s want %s: %q", ch, got, want, r.Downstream[ch].Reasons)
+		}
+	}
+	// With 4 upstream channels, 52 dBmV is above the recommended maximum.
+	for ch, want := range map[modem.Channel]Grade{
+		"1": Marginal,
+		"2": Good,
+		"3": Good,
+		"4": Marginal,
+	} {
+		if got := r.Upstream[ch].Grade; got != want {
+			t.Errorf("Upstream %s got %s want %s: %q", ch, got, want, r.Upstream[ch].Reasons)
+		}
+	}
+	if r.Overall != Bad {
+		t.Errorf("Overall got %s want %s", r.Overall, Bad)
+	}
+	if len(r.Downstream["1"].Reasons) != 0 {
+		t.Errorf("Good channel has reasons %q", r.Downstream["1"].Reasons)
+	}
+
+	s.Upstream = map[modem.Channel]*modem.Upstream{"1": {PowerLevel: 52}}
+	if got := DOCSIS().Evaluate(s).Upstream["1"].Grade; got != Good {
+		t.Errorf("Single upstream channel at 52 dBmV got %s want %s", got, Good)
+	}
+}
+
+func TestReadThresholds(t *testing.T) {
+	f, err := ioutil.TempFile("", "thresholds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString(`{
+		"downstream_snr": {"QAM256": {"good": 35, "marginal": 32}, "QAM64": {"good": 28}, "1024QAM": {"good": 40}},
+		"upstream_power": {"4": {"good": {"max": 50}}, "8": {"marginal": {"max": 52}}}
+	}`)
+	f.Close()
+
+	th, err := ReadThresholds(f.Name())
+	if err != nil {
+		t.Fatalf("ReadThresholds failed: %v", err)
+	}
+	def := DOCSIS()
+	for _, tc := range []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"QAM256 SNR", th.DownstreamSNR["QAM256"], Floor{Good: 35, Marginal: 32}},
+		// Fields missing from an entry keep their defaults.
+		{"QAM64 SNR", th.DownstreamSNR["QAM64"], Floor{Good: 28, Marginal: 24}},
+		// New modulations start from the default SNR.
+		{"QAM1024 SNR", th.DownstreamSNR["QAM1024"], Floor{Good: 40, Marginal: def.DefaultSNR.Marginal}},
+		{"OFDM SNR", th.DownstreamSNR["OFDM"], def.DownstreamSNR["OFDM"]},
+		{"downstream power", th.DownstreamPower, def.DownstreamPower},
+		{"4 channel upstream power", th.UpstreamPower[4], Limits{Good: Range{35, 50}, Marginal: Range{30, 54}}},
+		// New counts start from the largest count below.
+		{"8 channel upstream power", th.UpstreamPower[8], Limits{Good: Range{35, 50}, Marginal: Range{30, 52}}},
+		{"1 channel upstream power", th.UpstreamPower[1], def.UpstreamPower[1]},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s got %+v want %+v", tc.name, tc.got, tc.want)
+		}
+	}
+}
+
+func TestUpstreamPowerBeyondFourChannels(t *testing.T) {
+	s := &modem.Signal{Upstream: map[modem.Channel]*modem.Upstream{}}
+	for i := 1; i <= 8; i++ {
+		s.Upstream[modem.Channel(strconv.Itoa(i))] = &modem.Upstream{PowerLevel: 52}
+	}
+	r := DOCSIS().Evaluate(s)
+	if got, want := r.Upstream["1"].Reasons, []string{"marginal: power 52.0 dBmV outside 35 to 51 for 8 channels"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Reasons got %q want %q", got, want)
+	}
+}
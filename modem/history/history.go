@@ -0,0 +1,98 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package history keeps recent samples of a modem.Signal in memory and serves
+// them as per-channel time series.
+package history
+
+import (
+	"sync"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+)
+
+// Sample is a modem.Signal and the time it was read.
+type Sample struct {
+	Time   time.Time
+	Signal *modem.Signal
+}
+
+// Ring holds the most recent samples within a time window, up to a fixed
+// number of them.  It is safe for concurrent use.
+type Ring struct {
+	mu     sync.Mutex
+	window time.Duration
+	buf    []Sample
+	// start is the index of the oldest sample in buf, and n the number of
+	// samples held.
+	start, n int
+}
+
+// NewRing returns a Ring keeping samples no older than window, and at most
+// max of them.
+func NewRing(window time.Duration, max int) *Ring {
+	return &Ring{window: window, buf: make([]Sample, max)}
+}
+
+// Add records s as read at t.  Samples must be added in time order.
+func (r *Ring) Add(t time.Time, s *modem.Signal) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.buf) == 0 {
+		return
+	}
+	if r.n == len(r.buf) {
+		r.start = (r.start + 1) % len(r.buf)
+		r.n--
+	}
+	r.buf[(r.start+r.n)%len(r.buf)] = Sample{Time: t, Signal: s}
+	r.n++
+	// Expire samples that fell out of the window.
+	for r.n > 0 && t.Sub(r.buf[r.start].Time) > r.window {
+		r.buf[r.start] = Sample{}
+		r.start = (r.start + 1) % len(r.buf)
+		r.n--
+	}
+}
+
+// Source is a store of samples.
+type Source interface {
+	// Range returns the samples read from from to to inclusive, oldest
+	// first.
+	Range(from, to time.Time) ([]Sample, error)
+	// Window returns how long samples are kept.
+	Window() time.Duration
+}
+
+// Range returns the samples read from from to to inclusive, oldest first.  It
+// never fails.
+func (r *Ring) Range(from, to time.Time) ([]Sample, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var samples []Sample
+	for i := 0; i < r.n; i++ {
+		s := r.buf[(r.start+i)%len(r.buf)]
+		if s.Time.Before(from) || s.Time.After(to) {
+			continue
+		}
+		samples = append(samples, s)
+	}
+	return samples, nil
+}
+
+// Window returns the time window r keeps.
+func (r *Ring) Window() time.Duration {
+	return r.window
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+)
+
+var t0 = time.Unix(1000, 0).UTC()
+
+// signal returns a Signal whose values are all derived from i.
+func signal(i int) *modem.Signal {
+	v := float64(i)
+	return &modem.Signal{
+		Downstream: map[modem.Channel]*modem.Downstream{
+			"1": {PowerLevel: v, SNR: 30 + v, Correctable: 10 * v},
+			"2": {PowerLevel: -v, SNR: 30 - v, Correctable: 20 * v},
+		},
+		Upstream: map[modem.Channel]*modem.Upstream{
+			"1": {PowerLevel: 40 + v, SymbolRate: 5120000},
+		},
+	}
+}
+
+// times returns the seconds after t0 of the samples r holds from from to to.
+func times(t *testing.T, r *Ring, from, to time.Time) []int {
+	t.Helper()
+	samples, err := r.Range(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var secs []int
+	for _, s := range samples {
+		secs = append(secs, int(s.Time.Sub(t0)/time.Second))
+	}
+	return secs
+}
+
+func TestRing(t *testing.T) {
+	r := NewRing(time.Minute, 4)
+	for i := 0; i < 6; i++ {
+		r.Add(t0.Add(time.Duration(i)*10*time.Second), signal(i))
+	}
+	// Only the last 4 fit.
+	if got, want := times(t, r, t0, t0.Add(time.Hour)), []int{20, 30, 40, 50}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Range got %v want %v", got, want)
+	}
+	if got, want := times(t, r, t0.Add(25*time.Second), t0.Add(40*time.Second)), []int{30, 40}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Partial range got %v want %v", got, want)
+	}
+
+	// Samples older than a minute expire.
+	r.Add(t0.Add(100*time.Second), signal(10))
+	if got, want := times(t, r, t0, t0.Add(time.Hour)), []int{40, 50, 100}; !reflect.DeepEqual(got, want) {
+		t.Errorf("After expiry got %v want %v", got, want)
+	}
+}
+
+func TestNewSeries(t *testing.T) {
+	var samples []Sample
+	for i := 0; i < 4; i++ {
+		samples = append(samples, Sample{Time: t0.Add(time.Duration(i) * time.Second), Signal: signal(i)})
+	}
+	s := NewSeries(samples, t0, t0.Add(4*time.Second), "", 10)
+	if s.Step != 0 {
+		t.Errorf("Step got %v want 0", s.Step)
+	}
+	if got := len(s.Downstream["2"]); got != 4 {
+		t.Errorf("Got %d points want 4", got)
+	}
+
+	s = NewSeries(samples, t0, t0.Add(4*time.Second), "1", 2)
+	if got, want := s.Step, 2*time.Second; got != want {
+		t.Errorf("Step got %v want %v", got, want)
+	}
+	wantDown := []DownstreamPoint{
+		{Time: t0.Add(time.Second), PowerLevel: 0.5, SNR: 30.5, Correctable: 10},
+		{Time: t0.Add(3 * time.Second), PowerLevel: 2.5, SNR: 32.5, Correctable: 30},
+	}
+	if got := s.Downstream["1"]; !reflect.DeepEqual(got, wantDown) {
+		t.Errorf("Downstream got %+v want %+v", got, wantDown)
+	}
+	if _, ok := s.Downstream["2"]; ok {
+		t.Errorf("Channel 2 not filtered out")
+	}
+	wantUp := []UpstreamPoint{
+		{Time: t0.Add(time.Second), PowerLevel: 40.5, SymbolRate: 5120000},
+		{Time: t0.Add(3 * time.Second), PowerLevel: 42.5, SymbolRate: 5120000},
+	}
+	if got := s.Upstream["1"]; !reflect.DeepEqual(got, wantUp) {
+		t.Errorf("Upstream got %+v want %+v", got, wantUp)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	r := NewRing(time.Hour, 100)
+	for i := 0; i < 10; i++ {
+		r.Add(t0.Add(time.Duration(i)*time.Second), signal(i))
+	}
+	w := httptest.NewRecorder()
+	u := fmt.Sprintf("/api/v1/history?from=%d&to=%s&channel=2&points=0", t0.Unix()+5, t0.Add(7*time.Second).Format(time.RFC3339))
+	Handler(r).ServeHTTP(w, httptest.NewRequest("GET", u, nil))
+	var s Series
+	if err := json.Unmarshal(w.Body.Bytes(), &s); err != nil {
+		t.Fatalf("Failed to decode %q: %v", w.Body, err)
+	}
+	if got := len(s.Downstream["2"]); got != 3 {
+		t.Errorf("Got %d points want 3", got)
+	}
+	if len(s.Downstream) != 1 || len(s.Upstream) != 0 {
+		t.Errorf("Got channels %v and %v, want only downstream 2", s.Downstream, s.Upstream)
+	}
+
+	for _, q := range []string{"from=yesterday", "to=x", "points=-1", "from=20&to=10"} {
+		w := httptest.NewRecorder()
+		Handler(r).ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/history?"+q, nil))
+		if w.Code != 400 {
+			t.Errorf("%s got status %d want 400", q, w.Code)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+)
+
+// DownstreamPoint is a downstream channel's values at a point in time.
+type DownstreamPoint struct {
+	Time          time.Time `json:"time"`
+	PowerLevel    float64   `json:"power_level"`
+	SNR           float64   `json:"snr"`
+	Unerrored     float64   `json:"unerrored"`
+	Correctable   float64   `json:"correctable"`
+	Uncorrectable float64   `json:"uncorrectable"`
+}
+
+// UpstreamPoint is an upstream channel's values at a point in time.
+type UpstreamPoint struct {
+	Time       time.Time `json:"time"`
+	PowerLevel float64   `json:"power_level"`
+	SymbolRate float64   `json:"symbol_rate"`
+}
+
+// Series holds per-channel time series.
+type Series struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// Step is the width of the buckets samples were averaged over, or zero
+	// if they weren't downsampled.
+	Step       time.Duration                       `json:"step"`
+	Downstream map[modem.Channel][]DownstreamPoint `json:"downstream"`
+	Upstream   map[modem.Channel][]UpstreamPoint   `json:"upstream"`
+}
+
+// bucket returns the samples grouped so there are at most points groups,
+// each spanning an equal part of from to to.  If there are no more than
+// points samples, each is in its own group and step is zero.
+func bucket(samples []Sample, from, to time.Time, points int) (groups [][]Sample, step time.Duration) {
+	if points <= 0 || len(samples) <= points {
+		for _, s := range samples {
+			groups = append(groups, []Sample{s})
+		}
+		return groups, 0
+	}
+	step = to.Sub(from) / time.Duration(points)
+	if step <= 0 {
+		step = 1
+	}
+	last := -1
+	for _, s := range samples {
+		b := int(s.Time.Sub(from) / step)
+		if b != last {
+			groups = append(groups, nil)
+			last = b
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], s)
+	}
+	return groups, step
+}
+
+// NewSeries returns the time series of samples between from and to,
+// limited to channel if it's not empty.  If there are more than points
+// samples, they're downsampled to at most points per channel: levels are
+// averaged, and counters take their last value, over equal spans of time.
+func NewSeries(samples []Sample, from, to time.Time, channel modem.Channel, points int) *Series {
+	groups, step := bucket(samples, from, to, points)
+	s := &Series{
+		From:       from,
+		To:         to,
+		Step:       step,
+		Downstream: map[modem.Channel][]DownstreamPoint{},
+		Upstream:   map[modem.Channel][]UpstreamPoint{},
+	}
+	for _, g := range groups {
+		ds := map[modem.Channel]*DownstreamPoint{}
+		dn := map[modem.Channel]float64{}
+		us := map[modem.Channel]*UpstreamPoint{}
+		un := map[modem.Channel]float64{}
+		for _, sample := range g {
+			for ch, d := range sample.Signal.Downstream {
+				if channel != "" && ch != channel {
+					continue
+				}
+				p, ok := ds[ch]
+				if !ok {
+					p = &DownstreamPoint{}
+					ds[ch] = p
+				}
+				p.Time = sample.Time
+				p.PowerLevel += d.PowerLevel
+				p.SNR += d.SNR
+				p.Unerrored, p.Correctable, p.Uncorrectable = d.Unerrored, d.Correctable, d.Uncorrectable
+				dn[ch]++
+			}
+			for ch, u := range sample.Signal.Upstream {
+				if channel != "" && ch != channel {
+					continue
+				}
+				p, ok := us[ch]
+				if !ok {
+					p = &UpstreamPoint{}
+					us[ch] = p
+				}
+				p.Time = sample.Time
+				p.PowerLevel += u.PowerLevel
+				p.SymbolRate += u.SymbolRate
+				un[ch]++
+			}
+		}
+		for ch, p := range ds {
+			p.PowerLevel /= dn[ch]
+			p.SNR /= dn[ch]
+			s.Downstream[ch] = append(s.Downstream[ch], *p)
+		}
+		for ch, p := range us {
+			p.PowerLevel /= un[ch]
+			p.SymbolRate /= un[ch]
+			s.Upstream[ch] = append(s.Upstream[ch], *p)
+		}
+	}
+	return s
+}
+
+// defaultPoints is the number of points per channel served when the request
+// doesn't give one.
+const defaultPoints = 500
+
+// ParseTime parses an RFC 3339 time or Unix seconds.
+func ParseTime(v string) (time.Time, error) {
+	if secs, err := strconv.ParseFloat(v, 64); err == nil {
+		return time.Unix(0, int64(secs*1e9)), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+type handler struct {
+	src Source
+}
+
+// Handler returns an http.Handler serving the samples held by src as a JSON
+// Series.  The optional query parameters are:
+//   - from and to, RFC 3339 times or Unix seconds, default the whole window.
+//   - channel, a channel to limit the series to.
+//   - points, the most points per channel to return, default 500.  Zero
+//     disables downsampling.
+func Handler(src Source) http.Handler {
+	return &handler{src: src}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	to := time.Now()
+	from := to.Add(-h.src.Window())
+	var err error
+	if v := req.FormValue("from"); v != "" {
+		if from, err = ParseTime(v); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid from %q: %v", v, err), http.StatusBadRequest)
+			return
+		}
+	}
+	if v := req.FormValue("to"); v != "" {
+		if to, err = ParseTime(v); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid to %q: %v", v, err), http.StatusBadRequest)
+			return
+		}
+	}
+	if to.Before(from) {
+		http.Error(w, "from is after to", http.StatusBadRequest)
+		return
+	}
+	points := defaultPoints
+	if v := req.FormValue("points"); v != "" {
+		if points, err = strconv.Atoi(v); err != nil || points < 0 {
+			http.Error(w, fmt.Sprintf("Invalid points %q", v), http.StatusBadRequest)
+			return
+		}
+	}
+	samples, err := h.src.Range(from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s := NewSeries(samples, from, to, modem.Channel(req.FormValue("channel")), points)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
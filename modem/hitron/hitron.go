@@ -0,0 +1,233 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hitron scrapes status from Hitron CODA series gateways (e.g.
+// CODA-4582).  Unlike the HTML scraping drivers, these gateways serve channel
+// data as JSON from endpoints under /data/ that their status page script
+// renders client side.
+package hitron
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+
+	"github.com/wathiede/surfer/modem"
+)
+
+const (
+	baseURL     = "http://192.168.100.1"
+	dsPath      = "/data/dsinfo.asp"
+	usPath      = "/data/usinfo.asp"
+	sysInfoPath = "/data/getSysInfo.asp"
+)
+
+// dsInfo is an element of the array served from dsPath.  All values are
+// strings in the JSON.
+type dsInfo struct {
+	PortID         string `json:"portId"`
+	Frequency      string `json:"frequency"`
+	Modulation     string `json:"modulation"`
+	SignalStrength string `json:"signalStrength"`
+	SNR            string `json:"snr"`
+	ChannelID      string `json:"channelId"`
+	Correcteds     string `json:"correcteds"`
+	Uncorrect      string `json:"uncorrect"`
+}
+
+// usInfo is an element of the array served from usPath.
+type usInfo struct {
+	PortID         string `json:"portId"`
+	Frequency      string `json:"frequency"`
+	Bandwidth      string `json:"bandwidth"`
+	ModType        string `json:"modtype"`
+	ScdmaMode      string `json:"scdmaMode"`
+	SignalStrength string `json:"signalStrength"`
+	ChannelID      string `json:"channelId"`
+}
+
+// sysInfo is an element of the array served from sysInfoPath.
+type sysInfo struct {
+	SwVersion    string `json:"swVersion"`
+	SystemUptime string `json:"systemUptime"`
+}
+
+// modulations maps the downstream modulation index to a name, as done by the
+// gateway's status page script.  Unknown values are reported verbatim.
+var modulations = map[string]string{
+	"0": "QAM16",
+	"1": "QAM64",
+	"2": "QAM256",
+	"3": "QAM1024",
+	"4": "QAM32",
+	"5": "QAM128",
+	"6": "QPSK",
+}
+
+type hitron struct{}
+
+func (hitron) Name() string { return "Hitron CODA" }
+
+// Pages returns the URLs of the downstream and upstream channel data, and the
+// system information captured alongside them.
+func (hitron) Pages() []string {
+	return []string{baseURL + dsPath, baseURL + usPath, baseURL + sysInfoPath}
+}
+
+// Fetch gets u, which needs no login.
+func (hitron) Fetch(ctx context.Context, client *http.Client, u string) ([]byte, error) {
+	return modem.GetPage(ctx, client, u, nil)
+}
+
+func probe(ctx context.Context, client *http.Client) modem.Modem {
+	glog.Infof("Probing %q", baseURL+dsPath)
+	var ds []dsInfo
+	if err := get(ctx, client, dsPath, &ds); err != nil {
+		glog.Errorf("Failed to get downstream info: %v", err)
+		return nil
+	}
+	if len(ds) == 0 || ds[0].PortID == "" {
+		return nil
+	}
+	return New()
+}
+
+func init() {
+	modem.Register(probe)
+}
+
+// New returns a modem.Modem that fetches Hitron JSON data from the default
+// URL.
+func New() modem.Modem {
+	return &hitron{}
+}
+
+// NewFakeData returns a modem.Modem that will parse Hitron JSON data from
+// the fake data at path, e.g. a directory holding data/dsinfo.asp and
+// data/usinfo.asp, see modem.NewFakeTransport.
+func NewFakeData(path string) (modem.Modem, error) {
+	t, err := modem.NewFakeTransport(path)
+	if err != nil {
+		return nil, err
+	}
+	m := probe(context.Background(), &http.Client{Transport: t})
+	if m == nil {
+		return nil, fmt.Errorf("%q does not contain Hitron data", path)
+	}
+	return modem.WithTransport(m, t), nil
+}
+
+// get fetches the JSON at path and decodes it into v.
+func get(ctx context.Context, client *http.Client, path string, v interface{}) error {
+	req, err := http.NewRequest("GET", baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", path, resp.Status)
+	}
+	return decode(resp.Body, v)
+}
+
+func decode(r io.Reader, v interface{}) error {
+	b, err := ioutil.ReadAll(io.LimitReader(r, 1<<20))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// Status will return signal data parsed from the JSON endpoints.
+func (h *hitron) Status(ctx context.Context, client *http.Client) (*modem.Signal, error) {
+	var ds []dsInfo
+	if err := get(ctx, client, dsPath, &ds); err != nil {
+		return nil, fmt.Errorf("Failed to get downstream info: %v", err)
+	}
+	var us []usInfo
+	if err := get(ctx, client, usPath, &us); err != nil {
+		return nil, fmt.Errorf("Failed to get upstream info: %v", err)
+	}
+	s := toSignal(ds, us)
+	readSysInfo(ctx, client, s)
+	return s, nil
+}
+
+// readSysInfo sets the uptime and firmware version of s from the system
+// information, leaving them unset if it can't be read.
+func readSysInfo(ctx context.Context, client *http.Client, s *modem.Signal) {
+	var si []sysInfo
+	if err := get(ctx, client, sysInfoPath, &si); err != nil {
+		glog.Warningf("Failed to get system info: %v", err)
+		return
+	}
+	if len(si) == 0 {
+		return
+	}
+	s.Firmware = si[0].SwVersion
+	d, err := modem.ParseUptime(si[0].SystemUptime)
+	if err != nil {
+		glog.Warningf("Failed to parse uptime: %v", err)
+		return
+	}
+	s.Uptime = d
+}
+
+func toSignal(ds []dsInfo, us []usInfo) *modem.Signal {
+	s := &modem.Signal{
+		Downstream: map[modem.Channel]*modem.Downstream{},
+		Upstream:   map[modem.Channel]*modem.Upstream{},
+	}
+	for _, d := range ds {
+		m, ok := modulations[d.Modulation]
+		if !ok {
+			m = d.Modulation
+		}
+		s.Downstream[modem.Channel(d.PortID)] = &modem.Downstream{
+			Frequency:     d.Frequency,
+			Modulation:    m,
+			PowerLevel:    number(d.SignalStrength),
+			SNR:           number(d.SNR),
+			Correctable:   number(d.Correcteds),
+			Uncorrectable: number(d.Uncorrect),
+		}
+	}
+	for _, u := range us {
+		s.Upstream[modem.Channel(u.PortID)] = &modem.Upstream{
+			Frequency: u.Frequency,
+			// 6.4 MHz wide channels carry 5.12 Msym/sec.
+			SymbolRate: number(u.Bandwidth) / 1.25,
+			PowerLevel: number(u.SignalStrength),
+			Modulation: u.ModType,
+		}
+	}
+	return s
+}
+
+func number(s string) float64 {
+	f, _ := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return f
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hitron
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+)
+
+func TestStatus(t *testing.T) {
+	p := "testdata/CODA-4582"
+	m, err := NewFakeData(p)
+	if err != nil {
+		t.Fatalf("Failed to load %q: %v", p, err)
+	}
+	got, err := m.Status(context.Background(), &http.Client{})
+	if err != nil {
+		t.Fatalf("Failed to get status from %q: %v", p, err)
+	}
+
+	want := &modem.Signal{
+		Downstream: map[modem.Channel]*modem.Downstream{
+			"1": {
+				Frequency:   "591000000",
+				Modulation:  "QAM256",
+				PowerLevel:  6.1,
+				SNR:         40.366,
+				Correctable: 41,
+			},
+			"2": {
+				Frequency:   "597000000",
+				Modulation:  "QAM256",
+				PowerLevel:  5.9,
+				SNR:         40.366,
+				Correctable: 37,
+			},
+			"3": {
+				Frequency:     "603000000",
+				Modulation:    "QAM256",
+				PowerLevel:    5.7,
+				SNR:           40.946,
+				Correctable:   52,
+				Uncorrectable: 4,
+			},
+			"4": {
+				Frequency:   "609000000",
+				Modulation:  "QAM64",
+				PowerLevel:  5.4,
+				SNR:         37.636,
+				Correctable: 18,
+			},
+		},
+		Upstream: map[modem.Channel]*modem.Upstream{
+			"1": {
+				Frequency:  "38595785",
+				SymbolRate: 5.12e+06,
+				PowerLevel: 40.5,
+				Modulation: "64QAM",
+			},
+			"2": {
+				Frequency:  "32195785",
+				SymbolRate: 5.12e+06,
+				PowerLevel: 40.25,
+				Modulation: "64QAM",
+			},
+			"3": {
+				Frequency:  "23700000",
+				SymbolRate: 2.56e+06,
+				PowerLevel: 39.75,
+				Modulation: "64QAM",
+			},
+		},
+		Uptime:   3*24*time.Hour + 8*time.Hour + 5*time.Minute + 34*time.Second,
+		Firmware: "7.1.1.2.2b9",
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		g, _ := json.MarshalIndent(got, "", "  ")
+		w, _ := json.MarshalIndent(want, "", "  ")
+		t.Errorf("Got:\n%s\nWant:\n%s", g, w)
+	}
+}
+
+func TestNewFakeDataIgnoresOtherPaths(t *testing.T) {
+	for _, p := range []string{"testdata", "testdata/missing"} {
+		if m, err := NewFakeData(p); err == nil {
+			t.Errorf("NewFakeData(%q) = %v, want error", p, m)
+		}
+	}
+}
@@ -18,39 +18,142 @@ package modem
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
 )
 
 type Downstream struct {
-	Correctable float64
+	Correctable float64 `json:"correctable"`
 	// Hz
-	Frequency  string
-	Modulation string
+	Frequency  string `json:"frequency"`
+	Modulation string `json:"modulation"`
 	// dBmV
-	PowerLevel float64
+	PowerLevel float64 `json:"power_level"`
 	// dB
-	SNR           float64
-	Uncorrectable float64
-	Unerrored     float64
-	// TODO(wathiede): status?
+	SNR           float64 `json:"snr"`
+	Uncorrectable float64 `json:"uncorrectable"`
+	Unerrored     float64 `json:"unerrored"`
+	// Lock status, if reported by the modem.
+	Status string `json:"status,omitempty"`
 }
 
 type Upstream struct {
 	// Hz
-	Frequency string
+	Frequency string `json:"frequency"`
 	// Symbols / second
-	SymbolRate float64
+	SymbolRate float64 `json:"symbol_rate"`
 	// dBmV
-	PowerLevel float64
-	Modulation string
-	Status     string
+	PowerLevel float64 `json:"power_level"`
+	Modulation string  `json:"modulation"`
+	Status     string  `json:"status"`
 }
 
 type Channel string
 
+// SortChannels sorts chs in place, numerically where both channels are
+// numbers, otherwise lexically.  Numeric channels sort first.
+func SortChannels(chs []Channel) {
+	sort.Slice(chs, func(i, j int) bool {
+		a, aErr := strconv.Atoi(string(chs[i]))
+		b, bErr := strconv.Atoi(string(chs[j]))
+		switch {
+		case aErr == nil && bErr == nil:
+			return a < b
+		case aErr == nil:
+			return true
+		case bErr == nil:
+			return false
+		}
+		return chs[i] < chs[j]
+	})
+}
+
 type Signal struct {
-	Downstream map[Channel]*Downstream
-	Upstream   map[Channel]*Upstream
+	Downstream map[Channel]*Downstream `json:"downstream"`
+	Upstream   map[Channel]*Upstream   `json:"upstream"`
+	// Uptime is the time since the modem booted, or zero if the modem
+	// doesn't report it.
+	Uptime time.Duration `json:"uptime,omitempty"`
+	// Firmware is the modem's software version, or empty if the modem
+	// doesn't report it.
+	Firmware string `json:"firmware,omitempty"`
+	// Startup maps each step of the modem's startup procedure to its
+	// status, if the modem reports it.  The steps are redone, often with a
+	// different result, when the modem reboots.
+	Startup map[string]string `json:"startup,omitempty"`
+}
+
+var uptimeRE = regexp.MustCompile(`^(?:(\d+)\s*days?,?\s*)?(\d+)h?:(\d+)m?:(\d+)(?:\.\d+)?s?(?:\.\d+)?$`)
+
+// ParseUptime parses an uptime as shown by modems, like "7 days 04h:12m:34s",
+// "2 days 03:04:05" or "08h:05m:34s.00".
+func ParseUptime(s string) (time.Duration, error) {
+	m := uptimeRE.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if m == nil {
+		return 0, fmt.Errorf("unrecognized uptime %q", s)
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if m[i+1] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(m[i+1])
+		if err != nil {
+			return 0, fmt.Errorf("unrecognized uptime %q: %v", s, err)
+		}
+		d += time.Duration(n) * unit
+	}
+	return d, nil
+}
+
+// DownstreamChannels returns the downstream channels of s in SortChannels
+// order.
+func (s *Signal) DownstreamChannels() []Channel {
+	var chs []Channel
+	for ch := range s.Downstream {
+		chs = append(chs, ch)
+	}
+	SortChannels(chs)
+	return chs
+}
+
+// UpstreamChannels returns the upstream channels of s in SortChannels order.
+func (s *Signal) UpstreamChannels() []Channel {
+	var chs []Channel
+	for ch := range s.Upstream {
+		chs = append(chs, ch)
+	}
+	SortChannels(chs)
+	return chs
+}
+
+// Credentials are used to log in to modems whose status pages require
+// authentication.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+type credentialsKey struct{}
+
+// WithCredentials returns a copy of ctx carrying c.  Modem implementations
+// that require authentication retrieve them with CredentialsFromContext.
+func WithCredentials(ctx context.Context, c Credentials) context.Context {
+	return context.WithValue(ctx, credentialsKey{}, c)
+}
+
+// CredentialsFromContext returns the Credentials stored in ctx by
+// WithCredentials.  The boolean is false if ctx carries no credentials.
+func CredentialsFromContext(ctx context.Context) (Credentials, bool) {
+	c, ok := ctx.Value(credentialsKey{}).(Credentials)
+	return c, ok
 }
 
 type Modem interface {
@@ -58,36 +161,46 @@ type Modem interface {
 	// Fetch the status of the modem using implementation specific means.  The
 	// context.Context passed in can be used to set timeouts or cancel
 	// in-progress requests.
-	Status(context.Context, http.Client) (*Signal, error)
+	Status(context.Context, *http.Client) (*Signal, error)
 }
 
 // NewFunc is registered to determine if a given Modem is available for
 // parsing.
-// The ctx is used when making any requests.
-// Path is optional, if it is empty, implementations should probe their
-// configured URL.  If it is non-empty, the contents of the file should be
-// used to determine if it is a status page for the given Modem
-// implementation.
-// Implementations should return nil if path or the default URL do not
-// contain expected results.
-type NewFunc func(ctx context.Context, client http.Client, path string) Modem
+// The ctx and client are used when making any requests.
+// Implementations should return nil if their configured URL does not contain
+// expected results.
+type NewFunc func(ctx context.Context, client *http.Client) Modem
 
 var modems []NewFunc
 
 // New will walk the list of registered cable modems, and returns an instance
 // if any probers return successful.  Nil is returned if no probers succeed.
 // The ctx is used when making any requests.
-// Path is optional, if it is empty, implementations should probe their
-// configured URL.  If it is non-empty, the contents of the file should be
-// used to determine if it is a status page for the given Modem
-// implementation.
-func New(ctx context.Context, client http.Client, path string) Modem {
+// Path is optional, if it is empty, implementations probe their configured
+// URL.  If it is non-empty, requests are served from the fake data at path
+// instead, see NewFakeTransport, and the returned Modem continues to use it.
+func New(ctx context.Context, client *http.Client, path string) Modem {
+	var t http.RoundTripper
+	if path != "" {
+		var err error
+		if t, err = NewFakeTransport(path); err != nil {
+			glog.Errorf("Failed to load fake data: %v", err)
+			return nil
+		}
+		c := *client
+		c.Transport = t
+		client = &c
+	}
 	// TODO(wathiede): run in parallel and take the first that succeeds?
 	for _, f := range modems {
-		m := f(ctx, client, path)
-		if m != nil {
-			return m
+		m := f(ctx, client)
+		if m == nil {
+			continue
+		}
+		if t != nil {
+			m = WithTransport(m, t)
 		}
+		return m
 	}
 	return nil
 }
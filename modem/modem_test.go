@@ -0,0 +1,58 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modem
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSortChannels(t *testing.T) {
+	chs := []Channel{"10", "OFDM-1", "2", "33", "1", "OFDM-0"}
+	SortChannels(chs)
+	want := []Channel{"1", "2", "10", "33", "OFDM-0", "OFDM-1"}
+	if !reflect.DeepEqual(chs, want) {
+		t.Errorf("Got %v want %v", chs, want)
+	}
+}
+
+func TestParseUptime(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want time.Duration
+	}{
+		{"7 days 04h:12m:34s.00", 7*24*time.Hour + 4*time.Hour + 12*time.Minute + 34*time.Second},
+		{"0 days 01h:23m:45s", time.Hour + 23*time.Minute + 45*time.Second},
+		{"1 day 02:03:04", 26*time.Hour + 3*time.Minute + 4*time.Second},
+		{"3 Days,08h:05m:34s", 3*24*time.Hour + 8*time.Hour + 5*time.Minute + 34*time.Second},
+		{"08h:05m:34s", 8*time.Hour + 5*time.Minute + 34*time.Second},
+		{" 17:44:31 ", 17*time.Hour + 44*time.Minute + 31*time.Second},
+	} {
+		got, err := ParseUptime(tc.in)
+		if err != nil {
+			t.Errorf("ParseUptime(%q): %v", tc.in, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseUptime(%q): Got %v want %v", tc.in, got, tc.want)
+		}
+	}
+	for _, in := range []string{"", "N/A", "4 days", "12:34"} {
+		if got, err := ParseUptime(in); err == nil {
+			t.Errorf("ParseUptime(%q): Got %v want error", in, got)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package netgear scrapes status from the Netgear CM series (CM600, CM1000,
+// CM1200).  These modems do not render channel data as HTML tables, instead
+// the status page embeds it in JavaScript functions returning pipe-delimited
+// strings.
+package netgear
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+
+	"github.com/wathiede/surfer/modem"
+)
+
+// signalURLs are probed in order.  The CM600 serves an ASP page, the CM1000
+// and CM1200 serve the same content as static HTML.
+var signalURLs = []string{
+	"http://192.168.100.1/DocsisStatus.asp",
+	"http://192.168.100.1/DocsisStatus.htm",
+}
+
+var (
+	modelRE = regexp.MustCompile(`NETGEAR[^<]*?\b(CM\d{3,4})`)
+	// tagValueRE matches the body of a JavaScript function like:
+	//   function InitDsTableTagValue()
+	//   {
+	//       var tagValueList = '1|1|Locked|QAM256|...';
+	tagValueRE = regexp.MustCompile(`function\s+(Init\w*TagValue)\s*\(\s*\)\s*\{[^']*'([^']*)'`)
+)
+
+type netgear struct {
+	model     string
+	signalURL string
+}
+
+func (n *netgear) Name() string {
+	if n.model == "" {
+		return "Netgear"
+	}
+	return "Netgear " + n.model
+}
+
+// Pages returns the URLs of the status page, and the event log and product
+// information pages captured alongside it.  They share the status page's
+// extension.
+func (n *netgear) Pages() []string {
+	u := n.signalURL
+	if u == "" {
+		u = signalURLs[0]
+	}
+	ext := path.Ext(u)
+	base := strings.TrimSuffix(u, "DocsisStatus"+ext)
+	return []string{u, base + "EventLog" + ext, base + "RouterStatus" + ext}
+}
+
+// Fetch gets u like the status page, with HTTP basic auth.
+func (n *netgear) Fetch(ctx context.Context, client *http.Client, u string) ([]byte, error) {
+	return get(ctx, client, u)
+}
+
+func isNetgear(b []byte) bool {
+	return bytes.Contains(b, []byte("InitDsTableTagValue"))
+}
+
+func model(b []byte) string {
+	if m := modelRE.FindSubmatch(b); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
+
+func probe(ctx context.Context, client *http.Client) modem.Modem {
+	for _, u := range signalURLs {
+		glog.Infof("Probing %q", u)
+		b, err := get(ctx, client, u)
+		if err != nil {
+			glog.Errorf("Failed to get status page: %v", err)
+			continue
+		}
+		if isNetgear(b) {
+			return &netgear{model: model(b), signalURL: u}
+		}
+	}
+	return nil
+}
+
+func init() {
+	modem.Register(probe)
+}
+
+// New returns a modem.Modem that scrapes Netgear formatted data at the
+// default URL of the CM600.
+func New() modem.Modem {
+	return &netgear{signalURL: signalURLs[0]}
+}
+
+// NewFakeData returns a modem.Modem that will parse Netgear formatted data
+// from the fake data at path, see modem.NewFakeTransport.
+func NewFakeData(path string) (modem.Modem, error) {
+	t, err := modem.NewFakeTransport(path)
+	if err != nil {
+		return nil, err
+	}
+	m := probe(context.Background(), &http.Client{Transport: t})
+	if m == nil {
+		return nil, fmt.Errorf("%q does not contain Netgear data", path)
+	}
+	return modem.WithTransport(m, t), nil
+}
+
+// get fetches u, sending HTTP basic auth if ctx carries modem.Credentials.
+func get(ctx context.Context, client *http.Client, u string) ([]byte, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if c, ok := modem.CredentialsFromContext(ctx); ok {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	return ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// Status will return signal data parsed from the JavaScript in the
+// DocsisStatus page at the URL found while probing.
+func (n *netgear) Status(ctx context.Context, client *http.Client) (*modem.Signal, error) {
+	b, err := get(ctx, client, n.signalURL)
+	if err != nil {
+		return nil, err
+	}
+	return parseStatus(b)
+}
+
+func parseStatus(b []byte) (*modem.Signal, error) {
+	tags := map[string][]string{}
+	for _, m := range tagValueRE.FindAllSubmatch(b, -1) {
+		tags[string(m[1])] = strings.Split(string(m[2]), "|")
+	}
+	ds, ok := tags["InitDsTableTagValue"]
+	if !ok {
+		return nil, fmt.Errorf("Found no InitDsTableTagValue in status page")
+	}
+	us, ok := tags["InitUsTableTagValue"]
+	if !ok {
+		return nil, fmt.Errorf("Found no InitUsTableTagValue in status page")
+	}
+	signal := &modem.Signal{
+		Downstream: map[modem.Channel]*modem.Downstream{},
+		Upstream:   map[modem.Channel]*modem.Upstream{},
+	}
+	if err := parseDownstream(signal, ds); err != nil {
+		return nil, err
+	}
+	if err := parseUpstream(signal, us); err != nil {
+		return nil, err
+	}
+	if st, ok := tags["InitTagValue"]; ok {
+		signal.Startup = parseStartup(st)
+	}
+	// DOCSIS 3.1 models (CM1000, CM1200) list OFDM channels separately.
+	if ofdm, ok := tags["InitDsOfdmTableTagValue"]; ok {
+		if err := parseDownstreamOFDM(signal, ofdm); err != nil {
+			return nil, err
+		}
+	}
+	if ofdma, ok := tags["InitUsOfdmaTableTagValue"]; ok {
+		if err := parseUpstreamOFDMA(signal, ofdma); err != nil {
+			return nil, err
+		}
+	}
+	return signal, nil
+}
+
+// parseStartup returns the startup procedure from the InitTagValue list, or
+// nil if it's too short.
+func parseStartup(values []string) map[string]string {
+	// Frequency|Lock Status|Connectivity State|Boot State|Security|Current System Time|...
+	if len(values) < 5 {
+		return nil
+	}
+	return map[string]string{
+		"Acquire Downstream Channel": strings.TrimSpace(values[0] + " " + values[1]),
+		"Connectivity State":         values[2],
+		"Boot State":                 values[3],
+		"Security":                   values[4],
+	}
+}
+
+// rows splits a tag value list into rows of width fields.  The first element
+// of the list is the number of rows, and the list usually has a trailing
+// empty element from a terminating '|'.
+func rows(name string, values []string, width int) ([][]string, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("Empty %s table", name)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(values[0]))
+	if err != nil {
+		return nil, fmt.Errorf("Bad row count %q in %s table: %v", values[0], name, err)
+	}
+	values = values[1:]
+	if len(values) < n*width {
+		return nil, fmt.Errorf("Expected %d values for %d rows in %s table, got %d", n*width, n, name, len(values))
+	}
+	var r [][]string
+	for i := 0; i < n; i++ {
+		r = append(r, values[i*width:(i+1)*width])
+	}
+	return r, nil
+}
+
+// number parses the leading number from s, ignoring any unit suffix like
+// " Hz" or " dBmV".
+func number(s string) float64 {
+	fs := strings.Fields(s)
+	if len(fs) == 0 {
+		return 0
+	}
+	f, _ := strconv.ParseFloat(fs[0], 64)
+	return f
+}
+
+// frequency strips the unit suffix from s.
+func frequency(s string) string {
+	fs := strings.Fields(s)
+	if len(fs) == 0 {
+		return ""
+	}
+	return fs[0]
+}
+
+// locked reports whether a row describes a channel in use.  Netgear modems
+// always emit a fixed number of rows, padding with unlocked channels at 0 Hz.
+func locked(status, freq string) bool {
+	return status == "Locked" || number(freq) != 0
+}
+
+func parseDownstream(signal *modem.Signal, values []string) error {
+	// Channel|Lock Status|Modulation|Channel ID|Frequency|Power|SNR|Correctables|Uncorrectables
+	rs, err := rows("downstream", values, 9)
+	if err != nil {
+		return err
+	}
+	for _, r := range rs {
+		if !locked(r[1], r[4]) {
+			continue
+		}
+		signal.Downstream[modem.Channel(r[0])] = &modem.Downstream{
+			Status:        r[1],
+			Modulation:    r[2],
+			Frequency:     frequency(r[4]),
+			PowerLevel:    number(r[5]),
+			SNR:           number(r[6]),
+			Correctable:   number(r[7]),
+			Uncorrectable: number(r[8]),
+		}
+	}
+	return nil
+}
+
+func parseDownstreamOFDM(signal *modem.Signal, values []string) error {
+	// Channel|Lock Status|Profile ID|Channel ID|Frequency|Power|SNR/MER|Active Subcarrier Range|Unerrored|Correctables|Uncorrectables
+	rs, err := rows("downstream OFDM", values, 11)
+	if err != nil {
+		return err
+	}
+	for _, r := range rs {
+		if !locked(r[1], r[4]) {
+			continue
+		}
+		signal.Downstream[modem.Channel("OFDM-"+r[0])] = &modem.Downstream{
+			Status:        r[1],
+			Modulation:    "OFDM",
+			Frequency:     frequency(r[4]),
+			PowerLevel:    number(r[5]),
+			SNR:           number(r[6]),
+			Unerrored:     number(r[8]),
+			Correctable:   number(r[9]),
+			Uncorrectable: number(r[10]),
+		}
+	}
+	return nil
+}
+
+func parseUpstream(signal *modem.Signal, values []string) error {
+	// Channel|Lock Status|Channel Type|Channel ID|Symbol Rate|Frequency|Power
+	rs, err := rows("upstream", values, 7)
+	if err != nil {
+		return err
+	}
+	for _, r := range rs {
+		if !locked(r[1], r[5]) {
+			continue
+		}
+		signal.Upstream[modem.Channel(r[0])] = &modem.Upstream{
+			Status:     r[1],
+			Modulation: r[2],
+			// Reported in Ksym/sec.
+			SymbolRate: number(r[4]) * 1000,
+			Frequency:  frequency(r[5]),
+			PowerLevel: number(r[6]),
+		}
+	}
+	return nil
+}
+
+func parseUpstreamOFDMA(signal *modem.Signal, values []string) error {
+	// Channel|Lock Status|Profile ID|Channel ID|Frequency|Power
+	rs, err := rows("upstream OFDMA", values, 6)
+	if err != nil {
+		return err
+	}
+	for _, r := range rs {
+		if !locked(r[1], r[4]) {
+			continue
+		}
+		signal.Upstream[modem.Channel("OFDMA-"+r[0])] = &modem.Upstream{
+			Status:     r[1],
+			Modulation: "OFDMA",
+			Frequency:  frequency(r[4]),
+			PowerLevel: number(r[5]),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netgear
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/wathiede/surfer/modem"
+)
+
+func TestParseStatus(t *testing.T) {
+	for _, tc := range []struct {
+		path  string
+		model string
+		want  *modem.Signal
+	}{
+		{
+			path:  "testdata/CM600.html",
+			model: "CM600",
+			want: &modem.Signal{
+				Downstream: map[modem.Channel]*modem.Downstream{
+					"1": {Status: "Locked", Modulation: "QAM256", Frequency: "507000000", PowerLevel: 3.9, SNR: 40.9, Correctable: 17},
+					"2": {Status: "Locked", Modulation: "QAM256", Frequency: "435000000", PowerLevel: 4.6, SNR: 41.3, Correctable: 12},
+					"3": {Status: "Locked", Modulation: "QAM256", Frequency: "441000000", PowerLevel: 4.5, SNR: 41.2, Correctable: 9},
+					"4": {Status: "Locked", Modulation: "QAM256", Frequency: "447000000", PowerLevel: 4.4, SNR: 41.1, Correctable: 21, Uncorrectable: 3},
+					"5": {Status: "Locked", Modulation: "QAM256", Frequency: "453000000", PowerLevel: 4.2, SNR: 41, Correctable: 8},
+					"6": {Status: "Locked", Modulation: "QAM256", Frequency: "459000000", PowerLevel: 4.1, SNR: 40.9, Correctable: 11},
+					"7": {Status: "Locked", Modulation: "QAM256", Frequency: "465000000", PowerLevel: 4, SNR: 40.9, Correctable: 6},
+					"8": {Status: "Locked", Modulation: "QAM256", Frequency: "471000000", PowerLevel: 3.8, SNR: 40.8, Correctable: 14},
+				},
+				Upstream: map[modem.Channel]*modem.Upstream{
+					"1": {Frequency: "35600000", SymbolRate: 5.12e+06, PowerLevel: 41.5, Modulation: "ATDMA", Status: "Locked"},
+					"2": {Frequency: "29200000", SymbolRate: 5.12e+06, PowerLevel: 41, Modulation: "ATDMA", Status: "Locked"},
+					"3": {Frequency: "22800000", SymbolRate: 5.12e+06, PowerLevel: 40.5, Modulation: "ATDMA", Status: "Locked"},
+					"4": {Frequency: "18000000", SymbolRate: 2.56e+06, PowerLevel: 40.3, Modulation: "ATDMA", Status: "Locked"},
+				},
+				Startup: map[string]string{
+					"Acquire Downstream Channel": "507000000 Locked",
+					"Connectivity State":         "OK",
+					"Boot State":                 "Operation",
+					"Security":                   "BPI-Enabled",
+				},
+			},
+		},
+		{
+			path:  "testdata/CM1000.html",
+			model: "CM1000",
+			want: &modem.Signal{
+				Downstream: map[modem.Channel]*modem.Downstream{
+					"1":      {Status: "Locked", Modulation: "QAM256", Frequency: "627000000", PowerLevel: 1.8, SNR: 39.7, Correctable: 102, Uncorrectable: 14},
+					"2":      {Status: "Locked", Modulation: "QAM256", Frequency: "633000000", PowerLevel: 1.6, SNR: 39.6, Correctable: 96, Uncorrectable: 11},
+					"3":      {Status: "Locked", Modulation: "QAM256", Frequency: "639000000", PowerLevel: 1.4, SNR: 39.5, Correctable: 110, Uncorrectable: 9},
+					"OFDM-1": {Status: "Locked", Modulation: "OFDM", Frequency: "690000000", PowerLevel: 2.1, SNR: 41.3, Unerrored: 9451209, Correctable: 1203},
+				},
+				Upstream: map[modem.Channel]*modem.Upstream{
+					"1": {Frequency: "23700000", SymbolRate: 5.12e+06, PowerLevel: 44.3, Modulation: "ATDMA", Status: "Locked"},
+					"2": {Frequency: "30100000", SymbolRate: 5.12e+06, PowerLevel: 44.8, Modulation: "ATDMA", Status: "Locked"},
+				},
+				Startup: map[string]string{
+					"Acquire Downstream Channel": "627000000 Locked",
+					"Connectivity State":         "OK",
+					"Boot State":                 "Operation",
+					"Security":                   "BPI-Enabled",
+				},
+			},
+		},
+	} {
+		b, err := ioutil.ReadFile(tc.path)
+		if err != nil {
+			t.Fatalf("Failed to read %q: %v", tc.path, err)
+		}
+		if !isNetgear(b) {
+			t.Errorf("%s: isNetgear returned false", tc.path)
+		}
+		if got := model(b); got != tc.model {
+			t.Errorf("%s: model got %q want %q", tc.path, got, tc.model)
+		}
+		got, err := parseStatus(b)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", tc.path, err)
+		}
+		if !reflect.DeepEqual(tc.want, got) {
+			g, _ := json.MarshalIndent(got, "", "  ")
+			w, _ := json.MarshalIndent(tc.want, "", "  ")
+			t.Errorf("%s: Got:\n%s\nWant:\n%s", tc.path, g, w)
+		}
+	}
+}
+
+func TestParseStatusErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		page string
+	}{
+		{name: "no tables", page: `<html></html>`},
+		{
+			name: "short downstream",
+			page: `function InitDsTableTagValue() { var tagValueList = '2|1|Locked|QAM256|1|507000000 Hz|3.9|40.9|0|0|'; }
+function InitUsTableTagValue() { var tagValueList = '0|'; }`,
+		},
+		{
+			name: "bad count",
+			page: `function InitDsTableTagValue() { var tagValueList = 'x|'; }
+function InitUsTableTagValue() { var tagValueList = '0|'; }`,
+		},
+	} {
+		if _, err := parseStatus([]byte(tc.page)); err == nil {
+			t.Errorf("%s: expected error", tc.name)
+		}
+	}
+}
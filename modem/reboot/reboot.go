@@ -0,0 +1,92 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package reboot detects modem reboots by comparing successive samples of a
+// modem.Signal.
+package reboot
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+)
+
+// Event describes a detected reboot.
+type Event struct {
+	// Time is when the modem booted, if it reports its uptime, otherwise
+	// when the reboot was noticed.
+	Time time.Time `json:"time"`
+	// Reason is how the reboot was noticed.
+	Reason string `json:"reason"`
+}
+
+// Detector remembers the previous sample of a modem.Signal.  It is safe for
+// concurrent use.
+type Detector struct {
+	mu   sync.Mutex
+	prev *modem.Signal
+}
+
+// Update records s as sampled at t, and returns an Event if the modem
+// rebooted since the previous sample, or nil.  A reboot is detected when:
+//   - the modem's uptime went backwards,
+//   - the modem's startup procedure table changed, as its steps are redone
+//     when it boots, or
+//   - every downstream channel in both samples, that had counted any
+//     codewords, counted fewer this time.
+func (d *Detector) Update(t time.Time, s *modem.Signal) *Event {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	prev := d.prev
+	d.prev = s
+	if prev == nil {
+		return nil
+	}
+	if s.Uptime != 0 && prev.Uptime != 0 {
+		if s.Uptime < prev.Uptime {
+			return &Event{Time: t.Add(-s.Uptime), Reason: "uptime reset"}
+		}
+		// A reliable uptime overrides counters, which some modems
+		// reset on their own.
+		return nil
+	}
+	if len(s.Startup) != 0 && len(prev.Startup) != 0 && !reflect.DeepEqual(s.Startup, prev.Startup) {
+		return &Event{Time: t, Reason: "startup procedure changed"}
+	}
+	if countersReset(prev, s) {
+		e := &Event{Time: t, Reason: "codeword counters reset"}
+		if s.Uptime != 0 {
+			e.Time = t.Add(-s.Uptime)
+		}
+		return e
+	}
+	return nil
+}
+
+func countersReset(prev, cur *modem.Signal) bool {
+	compared := 0
+	for ch, c := range cur.Downstream {
+		p, ok := prev.Downstream[ch]
+		if !ok || p.Unerrored+p.Correctable+p.Uncorrectable == 0 {
+			continue
+		}
+		compared++
+		if c.Unerrored >= p.Unerrored && c.Correctable >= p.Correctable && c.Uncorrectable >= p.Uncorrectable {
+			return false
+		}
+	}
+	return compared > 0
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reboot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+)
+
+func signal(uptime time.Duration, correctable ...float64) *modem.Signal {
+	s := &modem.Signal{
+		Downstream: map[modem.Channel]*modem.Downstream{},
+		Uptime:     uptime,
+	}
+	for i, c := range correctable {
+		s.Downstream[modem.Channel(string(rune('1'+i)))] = &modem.Downstream{Correctable: c}
+	}
+	return s
+}
+
+// withStartup sets the downstream channel acquired during startup of s.
+func withStartup(s *modem.Signal, freq string) *modem.Signal {
+	s.Startup = map[string]string{
+		"Acquire Downstream Channel": freq + " Hz Locked",
+		"Boot State":                 "OK Operational",
+	}
+	return s
+}
+
+func TestUpdate(t *testing.T) {
+	t0 := time.Unix(1000, 0)
+	for _, tc := range []struct {
+		name      string
+		prev, cur *modem.Signal
+		want      *Event
+	}{
+		{
+			name: "counters advance",
+			prev: signal(0, 10, 20),
+			cur:  signal(0, 11, 20),
+		},
+		{
+			name: "all counters reset",
+			prev: signal(0, 10, 20),
+			cur:  signal(0, 1, 0),
+			want: &Event{Time: t0, Reason: "codeword counters reset"},
+		},
+		{
+			name: "one counter reset",
+			prev: signal(0, 10, 20),
+			cur:  signal(0, 1, 21),
+		},
+		{
+			name: "channels that counted nothing are ignored",
+			prev: signal(0, 10, 0),
+			cur:  signal(0, 1, 0),
+			want: &Event{Time: t0, Reason: "codeword counters reset"},
+		},
+		{
+			name: "no counts",
+			prev: signal(0, 0),
+			cur:  signal(0, 0),
+		},
+		{
+			name: "uptime reset",
+			prev: signal(time.Hour, 10),
+			cur:  signal(time.Minute, 11),
+			want: &Event{Time: t0.Add(-time.Minute), Reason: "uptime reset"},
+		},
+		{
+			name: "uptime overrides counters",
+			prev: signal(time.Hour, 10),
+			cur:  signal(time.Hour+time.Minute, 1),
+		},
+		{
+			name: "startup procedure unchanged",
+			prev: withStartup(signal(0, 10, 20), "639000000"),
+			cur:  withStartup(signal(0, 11, 20), "639000000"),
+		},
+		{
+			name: "startup procedure changed",
+			prev: withStartup(signal(0, 10, 20), "639000000"),
+			cur:  withStartup(signal(0, 11, 20), "645000000"),
+			want: &Event{Time: t0, Reason: "startup procedure changed"},
+		},
+		{
+			name: "startup procedure first reported",
+			prev: signal(0, 10, 20),
+			cur:  withStartup(signal(0, 11, 20), "639000000"),
+		},
+		{
+			name: "uptime overrides startup procedure",
+			prev: withStartup(signal(time.Hour, 10), "639000000"),
+			cur:  withStartup(signal(time.Hour+time.Minute, 11), "645000000"),
+		},
+	} {
+		d := &Detector{}
+		if e := d.Update(t0.Add(-time.Minute), tc.prev); e != nil {
+			t.Errorf("%s: first sample got event %+v", tc.name, e)
+		}
+		got := d.Update(t0, tc.cur)
+		switch {
+		case got == nil && tc.want == nil:
+		case got == nil || tc.want == nil || *got != *tc.want:
+			t.Errorf("%s: got %+v want %+v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -18,6 +18,7 @@ package sb6121
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -32,7 +33,13 @@ import (
 	"github.com/wathiede/surfer/modem"
 )
 
-const signalURL = "http://192.168.100.1/cmSignalData.htm"
+const (
+	// indexURL is the frameset holding the other pages.
+	indexURL    = "http://192.168.100.1/"
+	signalURL   = "http://192.168.100.1/cmSignalData.htm"
+	swInfoURL   = "http://192.168.100.1/cmHelpData.htm"
+	eventLogURL = "http://192.168.100.1/cmLogsData.htm"
+)
 
 type downstreamStat struct {
 	frequency  string
@@ -56,33 +63,26 @@ type upstreamStat struct {
 	powerLevel     float64
 }
 
-type sb6121 struct {
-	fakeData []byte
-}
+type sb6121 struct{}
 
 func (sb6121) Name() string { return "SB6121" }
 
+// Pages returns the URLs of the status page, and the frameset, product
+// information and event log pages captured alongside it.
+func (sb6121) Pages() []string {
+	return []string{signalURL, indexURL, swInfoURL, eventLogURL}
+}
+
+// Fetch gets u, which needs no login.
+func (sb6121) Fetch(ctx context.Context, client *http.Client, u string) ([]byte, error) {
+	return modem.GetPage(ctx, client, u, nil)
+}
+
 func isSB6121(b []byte) bool {
 	return bytes.Contains(b, []byte(`<META content="Microsoft FrontPage 4.0" name=GENERATOR>`))
 }
 
-func probe(ctx context.Context, client http.Client, path string) modem.Modem {
-	if path != "" {
-		b, err := ioutil.ReadFile(path)
-		if err != nil {
-			glog.Errorf("Failed to read %q: %v", path, err)
-			return nil
-		}
-		if isSB6121(b) {
-			m, err := NewFakeData(path)
-			if err != nil {
-				glog.Errorf("Failed to create fake SB6121: %v", err)
-				return nil
-			}
-			return m
-		}
-		return nil
-	}
+func probe(ctx context.Context, client *http.Client) modem.Modem {
 	glog.Infof("Probing %q", signalURL)
 	rc, err := get(ctx, client)
 	if err != nil {
@@ -112,22 +112,27 @@ func New() modem.Modem {
 }
 
 // NewFakeData returns a modem.Modem that will parse SB6121 formatted data
-// from the HTML file given in path.
+// from the fake data at path, see modem.NewFakeTransport.
 func NewFakeData(path string) (modem.Modem, error) {
-	b, err := ioutil.ReadFile(path)
+	t, err := modem.NewFakeTransport(path)
 	if err != nil {
 		return nil, err
 	}
-	return &sb6121{fakeData: b}, nil
+	m := probe(context.Background(), &http.Client{Transport: t})
+	if m == nil {
+		return nil, fmt.Errorf("%q does not contain SB6121 data", path)
+	}
+	return modem.WithTransport(m, t), nil
 }
 
-func get(ctx context.Context, client http.Client) (io.ReadCloser, error) {
+func get(ctx context.Context, client *http.Client) (io.ReadCloser, error) {
 	glog.V(2).Infof("Start Probing %q", signalURL)
 	defer glog.V(2).Infof("Done Probing %q", signalURL)
 	req, err := http.NewRequest("GET", signalURL, nil)
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -135,13 +140,9 @@ func get(ctx context.Context, client http.Client) (io.ReadCloser, error) {
 	return resp.Body, nil
 }
 
-// Status will return signal data parsed from an HTML status page.  If
-// sb.fakeData is not nil, the fake data is parsed.  If it is nil, then an
-// HTTP request is made to the default signal URL of a SB6121.
-func (sb *sb6121) Status(ctx context.Context, client http.Client) (*modem.Signal, error) {
-	if sb.fakeData != nil {
-		return parseStatus(bytes.NewReader(sb.fakeData))
-	}
+// Status will return signal data parsed from an HTML status page fetched from
+// the default signal URL of a SB6121.
+func (sb *sb6121) Status(ctx context.Context, client *http.Client) (*modem.Signal, error) {
 	rc, err := get(ctx, client)
 	if err != nil {
 		return nil, err
@@ -15,7 +15,9 @@
 package sb6121
 
 import (
+	"context"
 	"encoding/json"
+	"net/http"
 	"os"
 	"reflect"
 	"testing"
@@ -113,3 +115,26 @@ func TestParseStatus(t *testing.T) {
 		t.Errorf("Got:\n%s\nWant:\n%s", g, w)
 	}
 }
+
+func TestNewFakeData(t *testing.T) {
+	m, err := NewFakeData("testdata")
+	if err != nil {
+		t.Fatalf("NewFakeData failed: %v", err)
+	}
+	got, err := m.Status(context.Background(), &http.Client{})
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	r, err := os.Open("testdata/SB6121-signal.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	want, err := parseStatus(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Status from fake data differs from parsed page")
+	}
+}
@@ -15,12 +15,18 @@
 package sb6183
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"flag"
+	"io/ioutil"
+	"net/http"
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/wathiede/surfer/htmlutil"
 	"github.com/wathiede/surfer/modem"
 )
 
@@ -217,6 +223,14 @@ func TestParseStatus(t *testing.T) {
 				Status:     "Locked",
 			},
 		},
+		Startup: map[string]string{
+			"Acquire Downstream Channel":    "Locked",
+			"Connectivity State":            "OK Operational",
+			"Boot State":                    "OK Operational",
+			"Configuration File":            "OK",
+			"Security":                      "Enabled BPI+",
+			"DOCSIS Network Access Enabled": "Allowed",
+		},
 	}
 
 	if !reflect.DeepEqual(want, got) {
@@ -225,3 +239,52 @@ func TestParseStatus(t *testing.T) {
 		t.Errorf("Got:\n%s\nWant:\n%s", g, w)
 	}
 }
+
+func TestParseStatusRedacted(t *testing.T) {
+	p := "testdata/SB6183.html"
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		t.Fatalf("Failed to read %q: %v", p, err)
+	}
+	want, err := parseStatus(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("Failed to parse %q: %v", p, err)
+	}
+	got, err := parseStatus(bytes.NewReader(htmlutil.Redact(b)))
+	if err != nil {
+		t.Fatalf("Failed to parse redacted %q: %v", p, err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Redacted %q parsed differently", p)
+	}
+}
+
+func TestNewFakeData(t *testing.T) {
+	for p, want := range map[string]struct {
+		uptime   time.Duration
+		firmware string
+	}{
+		// A capture holding the product information page.
+		"testdata": {time.Hour + 23*time.Minute + 45*time.Second, "D30CM-OSPREY-2.4.0.1-GA-02-NOSH"},
+		// A single status page has no uptime or firmware.
+		"testdata/SB6183.html": {},
+	} {
+		m, err := NewFakeData(p)
+		if err != nil {
+			t.Fatalf("NewFakeData(%q) failed: %v", p, err)
+		}
+		s, err := m.Status(context.Background(), &http.Client{})
+		if err != nil {
+			t.Fatalf("Status of %q failed: %v", p, err)
+		}
+		if len(s.Downstream) == 0 || len(s.Upstream) == 0 {
+			t.Errorf("Status of %q has no channels", p)
+		}
+		if got := s.Uptime; got != want.uptime {
+			t.Errorf("Status of %q: Got uptime %v want %v", p, got, want.uptime)
+		}
+		if got := s.Firmware; got != want.firmware {
+			t.Errorf("Status of %q: Got firmware %q want %q", p, got, want.firmware)
+		}
+	}
+}
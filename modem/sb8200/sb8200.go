@@ -33,35 +33,32 @@ import (
 	"github.com/wathiede/surfer/modem"
 )
 
-const signalURL = "http://192.168.100.1/cmconnectionstatus.html"
+const (
+	signalURL   = "http://192.168.100.1/cmconnectionstatus.html"
+	swInfoURL   = "http://192.168.100.1/cmswinfo.html"
+	eventLogURL = "http://192.168.100.1/cmeventlog.html"
+)
 
-type sb8200 struct {
-	fakeData []byte
-}
+type sb8200 struct{}
 
 func (sb8200) Name() string { return "SB8200" }
 
+// Pages returns the URLs of the status page, and the product information and
+// event log pages captured alongside it.
+func (sb8200) Pages() []string {
+	return []string{signalURL, swInfoURL, eventLogURL}
+}
+
+// Fetch gets u, which needs no login.
+func (sb8200) Fetch(ctx context.Context, client *http.Client, u string) ([]byte, error) {
+	return modem.GetPage(ctx, client, u, nil)
+}
+
 func isSB8200(b []byte) bool {
 	return bytes.Contains(b, []byte(`<span id="thisModelNumberIs">SB8200</span>`))
 }
 
-func probe(ctx context.Context, client http.Client, path string) modem.Modem {
-	if path != "" {
-		b, err := ioutil.ReadFile(path)
-		if err != nil {
-			glog.Errorf("Failed to read %q: %v", path, err)
-			return nil
-		}
-		if isSB8200(b) {
-			m, err := NewFakeData(path)
-			if err != nil {
-				glog.Errorf("Failed to create fake SB8200: %v", err)
-				return nil
-			}
-			return m
-		}
-		return nil
-	}
+func probe(ctx context.Context, client *http.Client) modem.Modem {
 	glog.Infof("Probing %q", signalURL)
 	rc, err := get(ctx, client)
 	if err != nil {
@@ -91,16 +88,20 @@ func New() modem.Modem {
 }
 
 // NewFakeData returns a modem.Modem that will parse SB8200 formatted data
-// from the HTML file given in path.
+// from the fake data at path, see modem.NewFakeTransport.
 func NewFakeData(path string) (modem.Modem, error) {
-	b, err := ioutil.ReadFile(path)
+	t, err := modem.NewFakeTransport(path)
 	if err != nil {
 		return nil, err
 	}
-	return &sb8200{fakeData: b}, nil
+	m := probe(context.Background(), &http.Client{Transport: t})
+	if m == nil {
+		return nil, fmt.Errorf("%q does not contain SB8200 data", path)
+	}
+	return modem.WithTransport(m, t), nil
 }
 
-func get(ctx context.Context, client http.Client) (io.ReadCloser, error) {
+func get(ctx context.Context, client *http.Client) (io.ReadCloser, error) {
 	req, err := http.NewRequest("GET", signalURL, nil)
 	if err != nil {
 		return nil, err
@@ -113,20 +114,53 @@ func get(ctx context.Context, client http.Client) (io.ReadCloser, error) {
 	return resp.Body, nil
 }
 
-// Status will return signal data parsed from an HTML status page.  If
-// sb.fakeData is not nil, the fake data is parsed.  If it is nil, then an
-// HTTP request is made to the default signal URL of a SB8200.
-func (sb *sb8200) Status(ctx context.Context, client http.Client) (*modem.Signal, error) {
-	if sb.fakeData != nil {
-		return parseStatus(bytes.NewReader(sb.fakeData))
-	}
-
+// Status will return signal data parsed from an HTML status page fetched from
+// the default signal URL of a SB8200.
+func (sb *sb8200) Status(ctx context.Context, client *http.Client) (*modem.Signal, error) {
 	rc, err := get(ctx, client)
 	if err != nil {
 		return nil, err
 	}
 	defer rc.Close()
-	return parseStatus(rc)
+	s, err := parseStatus(rc)
+	if err != nil {
+		return nil, err
+	}
+	readSwInfo(ctx, client, s)
+	return s, nil
+}
+
+// readSwInfo sets the uptime and firmware version of s from the product
+// information page, leaving them unset if it can't be read.
+func readSwInfo(ctx context.Context, client *http.Client, s *modem.Signal) {
+	b, err := modem.GetPage(ctx, client, swInfoURL, nil)
+	if err != nil {
+		glog.Warningf("Failed to get product information page: %v", err)
+		return
+	}
+	n, err := html.Parse(bytes.NewReader(b))
+	if err != nil {
+		glog.Warningf("Failed to parse product information page: %v", err)
+		return
+	}
+	parseSwInfo(n, s)
+}
+
+func parseSwInfo(n *html.Node, s *modem.Signal) {
+	if v, ok := htmlutil.RowValue(n, "Software Version"); ok {
+		s.Firmware = v
+	}
+	v, ok := htmlutil.RowValue(n, "Up Time")
+	if !ok {
+		glog.V(1).Infof("No uptime on product information page")
+		return
+	}
+	d, err := modem.ParseUptime(v)
+	if err != nil {
+		glog.Warningf("Failed to parse uptime: %v", err)
+		return
+	}
+	s.Uptime = d
 }
 
 func parseStatus(r io.Reader) (*modem.Signal, error) {
@@ -138,6 +172,10 @@ func parseStatus(r io.Reader) (*modem.Signal, error) {
 	if len(tables) != 3 {
 		return nil, fmt.Errorf("Found %d simpleTables, expected 3", len(tables))
 	}
+	st, err := parseStartupTable(tables[0])
+	if err != nil {
+		return nil, err
+	}
 	d, err := parseDownstreamTable(tables[1])
 	if err != nil {
 		return nil, err
@@ -149,9 +187,37 @@ func parseStatus(r io.Reader) (*modem.Signal, error) {
 	return &modem.Signal{
 		Downstream: d,
 		Upstream:   u,
+		Startup:    st,
 	}, nil
 }
 
+func parseStartupTable(n *html.Node) (map[string]string, error) {
+	m := map[string]string{}
+	rows := cascadia.MustCompile("tr").MatchAll(n)
+	if len(rows) <= 2 {
+		return nil, fmt.Errorf("Expected more than 2 rows in startup table, got %d", len(rows))
+	}
+	for _, row := range rows[2:] {
+		var procedure string
+		var status []string
+		for i, col := range cascadia.MustCompile("td").MatchAll(row) {
+			v := htmlutil.GetText(col)
+			switch i {
+			case 0:
+				// Procedure
+				procedure = v
+			default:
+				// Status, Comment
+				if v != "" {
+					status = append(status, v)
+				}
+			}
+		}
+		m[procedure] = strings.Join(status, " ")
+	}
+	return m, nil
+}
+
 func parseDownstreamTable(n *html.Node) (map[modem.Channel]*modem.Downstream, error) {
 	m := map[modem.Channel]*modem.Downstream{}
 	rows := cascadia.MustCompile("tr").MatchAll(n)
@@ -15,12 +15,18 @@
 package sb8200
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"flag"
+	"io/ioutil"
+	"net/http"
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/wathiede/surfer/htmlutil"
 	"github.com/wathiede/surfer/modem"
 )
 
@@ -339,6 +345,14 @@ func TestParseStatus(t *testing.T) {
 				Status:     "Locked",
 			},
 		},
+		Startup: map[string]string{
+			"Acquire Downstream Channel":    "639000000 Hz Locked",
+			"Connectivity State":            "OK Operational",
+			"Boot State":                    "OK Operational",
+			"Configuration File":            "OK",
+			"Security":                      "Enabled BPI+",
+			"DOCSIS Network Access Enabled": "Allowed",
+		},
 	}
 
 	if !reflect.DeepEqual(want, got) {
@@ -347,3 +361,52 @@ func TestParseStatus(t *testing.T) {
 		t.Errorf("Got:\n%s\nWant:\n%s", g, w)
 	}
 }
+
+func TestParseStatusRedacted(t *testing.T) {
+	p := "testdata/SB8200.html"
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		t.Fatalf("Failed to read %q: %v", p, err)
+	}
+	want, err := parseStatus(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("Failed to parse %q: %v", p, err)
+	}
+	got, err := parseStatus(bytes.NewReader(htmlutil.Redact(b)))
+	if err != nil {
+		t.Fatalf("Failed to parse redacted %q: %v", p, err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Redacted %q parsed differently", p)
+	}
+}
+
+func TestNewFakeData(t *testing.T) {
+	for p, want := range map[string]struct {
+		uptime   time.Duration
+		firmware string
+	}{
+		// A capture holding the product information page.
+		"testdata": {7*24*time.Hour + 4*time.Hour + 12*time.Minute + 34*time.Second, "AB01.01.009.32_051619_183.0A.NSH"},
+		// A single status page has no uptime or firmware.
+		"testdata/SB8200.html": {},
+	} {
+		m, err := NewFakeData(p)
+		if err != nil {
+			t.Fatalf("NewFakeData(%q) failed: %v", p, err)
+		}
+		s, err := m.Status(context.Background(), &http.Client{})
+		if err != nil {
+			t.Fatalf("Status of %q failed: %v", p, err)
+		}
+		if len(s.Downstream) == 0 || len(s.Upstream) == 0 {
+			t.Errorf("Status of %q has no channels", p)
+		}
+		if got := s.Uptime; got != want.uptime {
+			t.Errorf("Status of %q: Got uptime %v want %v", p, got, want.uptime)
+		}
+		if got := s.Firmware; got != want.firmware {
+			t.Errorf("Status of %q: Got firmware %q want %q", p, got, want.firmware)
+		}
+	}
+}
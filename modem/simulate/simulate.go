@@ -0,0 +1,244 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package simulate wraps a Modem so that its signal evolves like a real
+// modem's over successive calls to Status.  It's meant for fake data, which
+// otherwise returns the same page every time, so dashboards and alerts can be
+// exercised without a modem misbehaving on cue.
+package simulate
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/wathiede/surfer/modem"
+)
+
+// Options control how a simulated signal evolves.
+type Options struct {
+	// Seed seeds the random source, so a simulation can be repeated.
+	Seed int64
+	// Jitter is the standard deviation, in dB, of the noise added to SNR
+	// and power levels.
+	Jitter float64
+	// Codewords is the mean number of codewords each downstream channel
+	// receives between calls to Status.
+	Codewords float64
+	// CorrectableRate and UncorrectableRate are the mean fractions of
+	// codewords that are correctable and uncorrectable.
+	CorrectableRate, UncorrectableRate float64
+	// RebootEvery is the mean number of calls to Status between reboots,
+	// which reset the codeword counters and uptime and acquire a new
+	// downstream channel in the startup procedure.  Zero disables reboots.
+	RebootEvery int
+	// LossEvery is the mean number of calls to Status between a downstream
+	// channel losing lock.  The channel is missing from the next
+	// LossDuration results.  Zero disables channel loss.
+	LossEvery    int
+	LossDuration int
+}
+
+// DefaultOptions returns Options resembling a healthy modem polled every 15
+// seconds, that reboots every few hours and drops a channel for a couple of
+// minutes about once an hour.
+func DefaultOptions() Options {
+	return Options{
+		Seed:              1,
+		Jitter:            0.3,
+		Codewords:         1e6,
+		CorrectableRate:   1e-5,
+		UncorrectableRate: 1e-7,
+		RebootEvery:       720,
+		LossEvery:         240,
+		LossDuration:      8,
+	}
+}
+
+type counters struct {
+	unerrored, correctable, uncorrectable float64
+}
+
+type simulator struct {
+	modem.Modem
+	o Options
+
+	mu   sync.Mutex
+	rand *rand.Rand
+	now  func() time.Time
+	// boot is when the simulated modem last booted.
+	boot time.Time
+	// counts holds the codewords simulated since the last reboot.
+	counts map[modem.Channel]*counters
+	// base holds the wrapped Modem's counters at the last reboot, which
+	// are subtracted so counters start over from zero.
+	base map[modem.Channel]counters
+	// lost holds the number of results each lost channel is still missing
+	// from.
+	lost map[modem.Channel]int
+	// acquired is the frequency of the downstream channel acquired at the
+	// last reboot, or empty if the simulated modem hasn't rebooted.
+	acquired string
+}
+
+// acquireStep is the startup step reporting the frequency of the downstream
+// channel acquired at boot.
+const acquireStep = "Acquire Downstream Channel"
+
+var frequencyRE = regexp.MustCompile(`^[0-9]+`)
+
+// New returns a Modem whose Status returns m's status with noise added to
+// levels, codeword counters that advance on every call, an uptime counted from
+// the call to New, and occasional reboots and channel loss, as configured by
+// o.
+func New(m modem.Modem, o Options) modem.Modem {
+	return newSimulator(m, o, time.Now)
+}
+
+func newSimulator(m modem.Modem, o Options, now func() time.Time) *simulator {
+	return &simulator{
+		Modem:  m,
+		o:      o,
+		rand:   rand.New(rand.NewSource(o.Seed)),
+		now:    now,
+		boot:   now(),
+		counts: map[modem.Channel]*counters{},
+		base:   map[modem.Channel]counters{},
+		lost:   map[modem.Channel]int{},
+	}
+}
+
+// Status returns the wrapped Modem's status, evolved as described in New.
+func (s *simulator) Status(ctx context.Context, client *http.Client) (*modem.Signal, error) {
+	sig, err := s.Modem.Status(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.o.RebootEvery > 0 && s.rand.Intn(s.o.RebootEvery) == 0 {
+		glog.Infof("Simulating reboot of %s", s.Name())
+		s.boot = s.now()
+		s.counts = map[modem.Channel]*counters{}
+		s.base = map[modem.Channel]counters{}
+		for ch, d := range sig.Downstream {
+			s.base[ch] = counters{d.Unerrored, d.Correctable, d.Uncorrectable}
+		}
+		// A reboot acquires a downstream channel afresh, which changes the
+		// startup procedure the way it does on a real modem.
+		if frequencyRE.MatchString(sig.Startup[acquireStep]) && len(sig.Downstream) > 0 {
+			chs := sig.DownstreamChannels()
+			s.acquired = frequencyRE.FindString(sig.Downstream[chs[s.rand.Intn(len(chs))]].Frequency)
+		}
+	}
+	if s.o.LossEvery > 0 && len(sig.Downstream) > 0 && s.rand.Intn(s.o.LossEvery) == 0 {
+		chs := sig.DownstreamChannels()
+		ch := chs[s.rand.Intn(len(chs))]
+		glog.Infof("Simulating loss of downstream channel %s for %d polls", ch, s.o.LossDuration)
+		s.lost[ch] = s.o.LossDuration
+	}
+
+	out := &modem.Signal{
+		Downstream: map[modem.Channel]*modem.Downstream{},
+		Upstream:   map[modem.Channel]*modem.Upstream{},
+		Uptime:     s.now().Sub(s.boot),
+		Firmware:   sig.Firmware,
+		Startup:    s.startup(sig.Startup),
+	}
+	// Iterate in channel order so a seed always gives the same results.
+	for _, ch := range sig.DownstreamChannels() {
+		if s.lost[ch] > 0 {
+			continue
+		}
+		d := *sig.Downstream[ch]
+		c, ok := s.counts[ch]
+		if !ok {
+			c = &counters{}
+			s.counts[ch] = c
+		}
+		n := math.Round(s.o.Codewords * (0.5 + s.rand.Float64()))
+		corr := math.Min(n, s.poisson(n*s.o.CorrectableRate))
+		uncorr := math.Min(n-corr, s.poisson(n*s.o.UncorrectableRate))
+		c.unerrored += n - corr - uncorr
+		c.correctable += corr
+		c.uncorrectable += uncorr
+
+		b := s.base[ch]
+		d.Unerrored = math.Max(0, d.Unerrored-b.unerrored) + c.unerrored
+		d.Correctable = math.Max(0, d.Correctable-b.correctable) + c.correctable
+		d.Uncorrectable = math.Max(0, d.Uncorrectable-b.uncorrectable) + c.uncorrectable
+		d.SNR = s.jitter(d.SNR)
+		d.PowerLevel = s.jitter(d.PowerLevel)
+		out.Downstream[ch] = &d
+	}
+	for ch, n := range s.lost {
+		if n <= 1 {
+			delete(s.lost, ch)
+			continue
+		}
+		s.lost[ch] = n - 1
+	}
+	for _, ch := range sig.UpstreamChannels() {
+		u := *sig.Upstream[ch]
+		u.PowerLevel = s.jitter(u.PowerLevel)
+		out.Upstream[ch] = &u
+	}
+	return out, nil
+}
+
+// startup returns a copy of steps reporting the downstream channel acquired at
+// the last simulated reboot.
+func (s *simulator) startup(steps map[string]string) map[string]string {
+	if steps == nil {
+		return nil
+	}
+	out := make(map[string]string, len(steps))
+	for k, v := range steps {
+		out[k] = v
+	}
+	if v, ok := out[acquireStep]; ok && s.acquired != "" {
+		out[acquireStep] = frequencyRE.ReplaceAllLiteralString(v, s.acquired)
+	}
+	return out
+}
+
+// jitter returns v plus normally distributed noise, rounded to the 0.1 dB
+// precision modems report.
+func (s *simulator) jitter(v float64) float64 {
+	return math.Round((v+s.rand.NormFloat64()*s.o.Jitter)*10) / 10
+}
+
+// poisson returns a Poisson distributed count with mean lambda, approximated
+// by a normal distribution for large means.
+func (s *simulator) poisson(lambda float64) float64 {
+	if lambda <= 0 {
+		return 0
+	}
+	if lambda > 30 {
+		return math.Max(0, math.Round(lambda+s.rand.NormFloat64()*math.Sqrt(lambda)))
+	}
+	l := math.Exp(-lambda)
+	k := 0.0
+	for p := s.rand.Float64(); p > l; p *= s.rand.Float64() {
+		k++
+	}
+	return k
+}
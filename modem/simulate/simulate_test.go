@@ -0,0 +1,177 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulate
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+)
+
+// static is a Modem that always returns the same signal, like fake data.
+type static struct{}
+
+func (static) Name() string { return "Static" }
+
+func (static) Status(context.Context, *http.Client) (*modem.Signal, error) {
+	return &modem.Signal{
+		Downstream: map[modem.Channel]*modem.Downstream{
+			"1": {Frequency: "591000000", SNR: 38, PowerLevel: 5, Unerrored: 1000, Correctable: 10},
+			"2": {Frequency: "597000000", SNR: 37, PowerLevel: 4, Unerrored: 2000, Correctable: 20},
+		},
+		Upstream: map[modem.Channel]*modem.Upstream{
+			"1": {Frequency: "38600000", PowerLevel: 45},
+		},
+	}, nil
+}
+
+// booted is a Modem that also reports its firmware and startup procedure.
+type booted struct{ static }
+
+func (booted) Status(ctx context.Context, client *http.Client) (*modem.Signal, error) {
+	sig, err := static{}.Status(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	sig.Firmware = "D31CM-PEREGRINE-1.0.0.0"
+	sig.Startup = map[string]string{
+		"Acquire Downstream Channel": "591000000 Hz Locked",
+		"Connectivity State":         "OK Operational",
+	}
+	return sig, nil
+}
+
+func statuses(t *testing.T, m modem.Modem, n int) []*modem.Signal {
+	t.Helper()
+	var sigs []*modem.Signal
+	for i := 0; i < n; i++ {
+		s, err := m.Status(context.Background(), &http.Client{})
+		if err != nil {
+			t.Fatalf("Status failed: %v", err)
+		}
+		sigs = append(sigs, s)
+	}
+	return sigs
+}
+
+func TestCountersAdvance(t *testing.T) {
+	o := DefaultOptions()
+	o.RebootEvery, o.LossEvery = 0, 0
+	sigs := statuses(t, New(static{}, o), 20)
+	for i := 1; i < len(sigs); i++ {
+		for ch, d := range sigs[i].Downstream {
+			prev := sigs[i-1].Downstream[ch]
+			if d.Unerrored <= prev.Unerrored || d.Correctable < prev.Correctable || d.Uncorrectable < prev.Uncorrectable {
+				t.Errorf("Poll %d channel %s counters went backwards: %+v after %+v", i, ch, d, prev)
+			}
+		}
+	}
+	if got, want := len(sigs[0].Upstream), 1; got != want {
+		t.Errorf("Got %d upstream channels want %d", got, want)
+	}
+}
+
+func TestReboot(t *testing.T) {
+	o := DefaultOptions()
+	o.RebootEvery, o.LossEvery = 1, 0
+	clock := time.Unix(1000, 0)
+	m := newSimulator(static{}, o, func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	})
+	for i, s := range statuses(t, m, 3) {
+		// The reboot reads the clock once, and so does computing uptime.
+		if got, want := s.Uptime, time.Second; got != want {
+			t.Errorf("Poll %d got uptime %v want %v", i, got, want)
+		}
+		for ch, d := range s.Downstream {
+			// Every poll reboots, so only one poll's worth of codewords is
+			// counted.
+			if d.Unerrored > 2*o.Codewords {
+				t.Errorf("Poll %d channel %s got %v unerrored codewords after reboot", i, ch, d.Unerrored)
+			}
+		}
+	}
+}
+
+func TestChannelLoss(t *testing.T) {
+	o := DefaultOptions()
+	o.RebootEvery, o.LossEvery, o.LossDuration = 0, 1, 2
+	for i, s := range statuses(t, New(static{}, o), 5) {
+		if got := len(s.Downstream); got >= 2 {
+			t.Errorf("Poll %d got %d downstream channels, want a lost channel", i, got)
+		}
+	}
+}
+
+func TestSeedRepeats(t *testing.T) {
+	o := DefaultOptions()
+	o.RebootEvery, o.LossEvery = 10, 10
+	now := func() time.Time { return time.Unix(1000, 0) }
+	a := statuses(t, newSimulator(static{}, o, now), 30)
+	b := statuses(t, newSimulator(static{}, o, now), 30)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("Simulations with the same seed differ")
+	}
+}
+
+func TestStartup(t *testing.T) {
+	o := DefaultOptions()
+	o.RebootEvery, o.LossEvery = 0, 0
+	want, err := booted{}.Status(context.Background(), &http.Client{})
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	for i, s := range statuses(t, New(booted{}, o), 3) {
+		if got := s.Firmware; got != want.Firmware {
+			t.Errorf("Poll %d got firmware %q want %q", i, got, want.Firmware)
+		}
+		if got := s.Startup; !reflect.DeepEqual(got, want.Startup) {
+			t.Errorf("Poll %d\nGot startup %v\nWant %v", i, got, want.Startup)
+		}
+	}
+}
+
+func TestStartupChangesOnReboot(t *testing.T) {
+	o := DefaultOptions()
+	o.RebootEvery, o.LossEvery = 3, 0
+	clock := time.Unix(1000, 0)
+	m := newSimulator(booted{}, o, func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	})
+	sigs := statuses(t, m, 30)
+	changed := 0
+	for i := 1; i < len(sigs); i++ {
+		rebooted := sigs[i].Uptime <= sigs[i-1].Uptime
+		acquired := sigs[i].Startup["Acquire Downstream Channel"]
+		if acquired != "591000000 Hz Locked" && acquired != "597000000 Hz Locked" {
+			t.Errorf("Poll %d acquired %q, want a downstream channel's frequency", i, acquired)
+		}
+		if acquired != sigs[i-1].Startup["Acquire Downstream Channel"] {
+			changed++
+			if !rebooted {
+				t.Errorf("Poll %d changed the startup procedure without rebooting", i)
+			}
+		}
+	}
+	if changed == 0 {
+		t.Errorf("The startup procedure never changed over %d polls", len(sigs))
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package store persists samples of a modem.Signal, and events derived from
+// them, to disk so history survives restarts.
+//
+// Records are kept in an embedded bbolt database, store.db in the store's
+// directory, keyed by time so a range is read without scanning the rest.
+// Records older than the retention period are deleted as new ones are added.
+package store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/modem/changes"
+	"github.com/wathiede/surfer/modem/history"
+	"github.com/wathiede/surfer/modem/reboot"
+)
+
+const (
+	dbFile = "store.db"
+	// expireEvery is how often Add deletes expired records.
+	expireEvery = time.Hour
+)
+
+var (
+	recordsBucket = []byte("records")
+	// lockTimeout is how long opening waits for another process holding
+	// the database.
+	lockTimeout = time.Second
+)
+
+// ErrLocked is returned by Open and OpenReadOnly when another process, e.g.
+// a serving surfer, has the store open for writing.
+var ErrLocked = errors.New("store is in use by another process")
+
+// Record is an entry in the store.  Exactly one of Signal, ChannelEvent and
+// Reboot is set.
+type Record struct {
+	Time         time.Time      `json:"time"`
+	Signal       *modem.Signal  `json:"signal,omitempty"`
+	ChannelEvent *changes.Event `json:"channel_event,omitempty"`
+	Reboot       *reboot.Event  `json:"reboot,omitempty"`
+}
+
+// Store keeps Records in a database file.  It is safe for concurrent use;
+// reads don't wait for writes.
+type Store struct {
+	db        *bolt.DB
+	retention time.Duration
+
+	mu      sync.Mutex
+	expired time.Time
+}
+
+// Open returns a Store keeping records in dir for retention, creating dir if
+// needed, and deletes records older than retention.
+func Open(dir string, retention time.Duration) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	db, err := open(dir, &bolt.Options{Timeout: lockTimeout})
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{db: db, retention: retention}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(recordsBucket); err != nil {
+			return err
+		}
+		return s.expire(tx, time.Now())
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// OpenReadOnly returns a Store reading the records in dir, which Add
+// refuses to change.  Nothing is expired, so a Store opened for reading
+// never loses records.
+func OpenReadOnly(dir string, retention time.Duration) (*Store, error) {
+	db, err := open(dir, &bolt.Options{Timeout: lockTimeout, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	return &Store{db: db, retention: retention}, nil
+}
+
+func open(dir string, o *bolt.Options) (*bolt.DB, error) {
+	p := filepath.Join(dir, dbFile)
+	db, err := bolt.Open(p, 0644, o)
+	if err == bolt.ErrTimeout {
+		return nil, fmt.Errorf("%s: %w", p, ErrLocked)
+	}
+	return db, err
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Window returns the retention period.
+func (s *Store) Window() time.Duration {
+	return s.retention
+}
+
+// key returns the key for a record at t.  Keys sort by time, then by seq so
+// records with the same time keep the order they were added in.
+func key(t time.Time, seq uint64) []byte {
+	k := make([]byte, 16)
+	binary.BigEndian.PutUint64(k, uint64(t.UnixNano()))
+	binary.BigEndian.PutUint64(k[8:], seq)
+	return k
+}
+
+// keyTime returns the time a key was made for.
+func keyTime(k []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(k)))
+}
+
+// expire deletes the records older than the retention period at now.
+func (s *Store) expire(tx *bolt.Tx, now time.Time) error {
+	s.mu.Lock()
+	s.expired = now
+	s.mu.Unlock()
+	b := tx.Bucket(recordsBucket)
+	cutoff := now.Add(-s.retention)
+	var old [][]byte
+	c := b.Cursor()
+	for k, _ := c.First(); k != nil && keyTime(k).Before(cutoff); k, _ = c.Next() {
+		old = append(old, k)
+	}
+	if len(old) > 0 {
+		glog.Infof("Deleting %d records older than %s", len(old), cutoff.Format(time.RFC3339))
+	}
+	for _, k := range old {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Add stores r, and deletes expired records if it's been a while.
+func (s *Store) Add(r Record) error {
+	v, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(recordsBucket)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		if err := b.Put(key(r.Time, seq), v); err != nil {
+			return err
+		}
+		s.mu.Lock()
+		due := r.Time.Sub(s.expired) >= expireEvery
+		s.mu.Unlock()
+		if due {
+			return s.expire(tx, r.Time)
+		}
+		return nil
+	})
+}
+
+// Records returns the records from from to to inclusive, oldest first.
+// Records that can't be parsed are skipped.
+func (s *Store) Records(from, to time.Time) ([]Record, error) {
+	var records []Record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(recordsBucket)
+		if b == nil {
+			// A read-only Store of a database never written to.
+			return nil
+		}
+		c := b.Cursor()
+		for k, v := c.Seek(key(from, 0)); k != nil && !keyTime(k).After(to); k, v = c.Next() {
+			var r Record
+			if err := json.Unmarshal(v, &r); err != nil {
+				glog.Warningf("Skipping record at %s: %v", keyTime(k).Format(time.RFC3339Nano), err)
+				continue
+			}
+			records = append(records, r)
+		}
+		return nil
+	})
+	return records, err
+}
+
+// Range returns the stored signal samples from from to to inclusive, oldest
+// first, so a Store can be served by history.Handler.
+func (s *Store) Range(from, to time.Time) ([]history.Sample, error) {
+	records, err := s.Records(from, to)
+	if err != nil {
+		return nil, err
+	}
+	var samples []history.Sample
+	for _, r := range records {
+		if r.Signal != nil {
+			samples = append(samples, history.Sample{Time: r.Time, Signal: r.Signal})
+		}
+	}
+	return samples, nil
+}
+
+type eventsHandler struct {
+	s *Store
+}
+
+// EventsHandler returns an http.Handler serving the channel and reboot
+// events in s as a JSON array of Records.  The optional from and to query
+// parameters, RFC 3339 times or Unix seconds, default to the whole retention
+// period.
+func EventsHandler(s *Store) http.Handler {
+	return &eventsHandler{s: s}
+}
+
+func (h *eventsHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	to := time.Now()
+	from := to.Add(-h.s.Window())
+	var err error
+	if v := req.FormValue("from"); v != "" {
+		if from, err = history.ParseTime(v); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid from %q: %v", v, err), http.StatusBadRequest)
+			return
+		}
+	}
+	if v := req.FormValue("to"); v != "" {
+		if to, err = history.ParseTime(v); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid to %q: %v", v, err), http.StatusBadRequest)
+			return
+		}
+	}
+	records, err := h.s.Records(from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	events := []Record{}
+	for _, r := range records {
+		if r.Signal == nil {
+			events = append(events, r)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(events); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
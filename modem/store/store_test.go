@@ -0,0 +1,172 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/modem/changes"
+	"github.com/wathiede/surfer/modem/reboot"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := Open(dir, 7*24*time.Hour)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	yesterday := now.AddDate(0, 0, -1)
+	sig := &modem.Signal{
+		Downstream: map[modem.Channel]*modem.Downstream{"1": {Frequency: "591000000", SNR: 38.5}},
+		Upstream:   map[modem.Channel]*modem.Upstream{},
+	}
+	want := []Record{
+		{Time: yesterday, Signal: sig},
+		{Time: yesterday.Add(time.Minute), Reboot: &reboot.Event{Time: yesterday, Reason: "uptime reset"}},
+		{Time: now, ChannelEvent: &changes.Event{Time: now, Direction: changes.Downstream, Channel: "1", Kind: changes.Added, New: "591000000"}},
+		{Time: now, Signal: sig},
+	}
+	// Records added out of order are read back in time order, and those
+	// at the same time in the order they were added.
+	for _, i := range []int{2, 0, 1, 3} {
+		if err := s.Add(want[i]); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = Open(dir, 7*24*time.Hour)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer s.Close()
+	got, err := s.Records(yesterday, now)
+	if err != nil {
+		t.Fatalf("Records failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		g, _ := json.MarshalIndent(got, "", "  ")
+		w, _ := json.MarshalIndent(want, "", "  ")
+		t.Errorf("Got:\n%s\nWant:\n%s", g, w)
+	}
+
+	samples, err := s.Range(now.Add(-time.Second), now)
+	if err != nil {
+		t.Fatalf("Range failed: %v", err)
+	}
+	if len(samples) != 1 || !samples[0].Time.Equal(now) {
+		t.Errorf("Range got %+v, want the sample at %v", samples, now)
+	}
+
+	w := httptest.NewRecorder()
+	EventsHandler(s).ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/events", nil))
+	var events []Record
+	if err := json.Unmarshal(w.Body.Bytes(), &events); err != nil {
+		t.Fatalf("Failed to decode %q: %v", w.Body, err)
+	}
+	if !reflect.DeepEqual(events, want[1:3]) {
+		t.Errorf("Events got %+v want %+v", events, want[1:3])
+	}
+}
+
+func TestExpire(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	old := Record{Time: now.AddDate(0, 0, -10), Reboot: &reboot.Event{Reason: "old"}}
+	recent := Record{Time: now.AddDate(0, 0, -1), Reboot: &reboot.Event{Reason: "recent"}}
+	s, err := Open(dir, 7*24*time.Hour)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	for _, r := range []Record{old, recent} {
+		if err := s.Add(r); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	s.Close()
+
+	// Reading never expires records.
+	s, err = OpenReadOnly(dir, 7*24*time.Hour)
+	if err != nil {
+		t.Fatalf("OpenReadOnly failed: %v", err)
+	}
+	got, err := s.Records(old.Time, now)
+	if err != nil {
+		t.Fatalf("Records failed: %v", err)
+	}
+	if want := []Record{old, recent}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Read-only got %+v want %+v", got, want)
+	}
+	if err := s.Add(Record{Time: now}); err == nil {
+		t.Errorf("Add to read-only store succeeded")
+	}
+	s.Close()
+
+	s, err = Open(dir, 7*24*time.Hour)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer s.Close()
+	got, err = s.Records(old.Time, now)
+	if err != nil {
+		t.Fatalf("Records failed: %v", err)
+	}
+	if want := []Record{recent}; !reflect.DeepEqual(got, want) {
+		t.Errorf("After reopening got %+v want %+v", got, want)
+	}
+}
+
+func TestLocked(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(d time.Duration) { lockTimeout = d }(lockTimeout)
+	lockTimeout = 10 * time.Millisecond
+
+	s, err := Open(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer s.Close()
+	if _, err := OpenReadOnly(dir, time.Hour); !errors.Is(err, ErrLocked) {
+		t.Errorf("OpenReadOnly of a store in use got %v want %v", err, ErrLocked)
+	}
+	if _, err := Open(dir, time.Hour); !errors.Is(err, ErrLocked) {
+		t.Errorf("Open of a store in use got %v want %v", err, ErrLocked)
+	}
+}
@@ -0,0 +1,344 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tc4400 scrapes status from the Technicolor TC4400.
+package tc4400
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/golang/glog"
+	"golang.org/x/net/html"
+
+	"github.com/wathiede/surfer/htmlutil"
+	"github.com/wathiede/surfer/modem"
+)
+
+const (
+	signalURL   = "http://192.168.100.1/cmconnectionstatus.html"
+	swInfoURL   = "http://192.168.100.1/cmswinfo.html"
+	eventLogURL = "http://192.168.100.1/cmeventlog.html"
+)
+
+// The TC4400 requires HTTP basic auth.  These factory defaults are used when
+// no modem.Credentials are given.
+const (
+	defaultUsername = "admin"
+	defaultPassword = "bEn2o#US9s"
+)
+
+type tc4400 struct{}
+
+func (tc4400) Name() string { return "TC4400" }
+
+// Pages returns the URLs of the status page, and the product information and
+// event log pages captured alongside it.
+func (tc4400) Pages() []string {
+	return []string{signalURL, swInfoURL, eventLogURL}
+}
+
+// Fetch gets u with the same HTTP basic auth as the status page.
+func (tc4400) Fetch(ctx context.Context, client *http.Client, u string) ([]byte, error) {
+	c := credentials(ctx)
+	return modem.GetPage(ctx, client, u, &c)
+}
+
+func isTC4400(b []byte) bool {
+	return bytes.Contains(b, []byte("Downstream Channel Status")) &&
+		bytes.Contains(b, []byte("SNR/MER Threshold Value"))
+}
+
+func probe(ctx context.Context, client *http.Client) modem.Modem {
+	glog.Infof("Probing %q", signalURL)
+	rc, err := get(ctx, client)
+	if err != nil {
+		glog.Errorf("Failed to get status page: %v", err)
+		return nil
+	}
+	defer rc.Close()
+	b, err := ioutil.ReadAll(io.LimitReader(rc, 1<<20))
+	if err != nil {
+		glog.Errorf("Failed to read status page: %v", err)
+		return nil
+	}
+	if isTC4400(b) {
+		return New()
+	}
+	return nil
+}
+
+func init() {
+	modem.Register(probe)
+}
+
+// New returns a modem.Modem that scrapes TC4400 formatted data at the default
+// URL.
+func New() modem.Modem {
+	return &tc4400{}
+}
+
+// NewFakeData returns a modem.Modem that will parse TC4400 formatted data
+// from the fake data at path, see modem.NewFakeTransport.
+func NewFakeData(path string) (modem.Modem, error) {
+	t, err := modem.NewFakeTransport(path)
+	if err != nil {
+		return nil, err
+	}
+	m := probe(context.Background(), &http.Client{Transport: t})
+	if m == nil {
+		return nil, fmt.Errorf("%q does not contain TC4400 data", path)
+	}
+	return modem.WithTransport(m, t), nil
+}
+
+// credentials returns the modem.Credentials in ctx, or the factory defaults.
+func credentials(ctx context.Context) modem.Credentials {
+	c, ok := modem.CredentialsFromContext(ctx)
+	if !ok {
+		c = modem.Credentials{Username: defaultUsername, Password: defaultPassword}
+	}
+	return c
+}
+
+func get(ctx context.Context, client *http.Client) (io.ReadCloser, error) {
+	req, err := http.NewRequest("GET", signalURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	c := credentials(ctx)
+	req.SetBasicAuth(c.Username, c.Password)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", signalURL, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// Status will return signal data parsed from an HTML status page fetched from
+// the default signal URL of a TC4400.
+func (tc *tc4400) Status(ctx context.Context, client *http.Client) (*modem.Signal, error) {
+	rc, err := get(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	s, err := parseStatus(rc)
+	if err != nil {
+		return nil, err
+	}
+	readSwInfo(ctx, client, s)
+	return s, nil
+}
+
+// readSwInfo sets the uptime and firmware version of s from the product
+// information page, leaving them unset if it can't be read.
+func readSwInfo(ctx context.Context, client *http.Client, s *modem.Signal) {
+	c := credentials(ctx)
+	b, err := modem.GetPage(ctx, client, swInfoURL, &c)
+	if err != nil {
+		glog.Warningf("Failed to get product information page: %v", err)
+		return
+	}
+	n, err := html.Parse(bytes.NewReader(b))
+	if err != nil {
+		glog.Warningf("Failed to parse product information page: %v", err)
+		return
+	}
+	parseSwInfo(n, s)
+}
+
+func parseSwInfo(n *html.Node, s *modem.Signal) {
+	if v, ok := htmlutil.RowValue(n, "Software Version"); ok {
+		s.Firmware = v
+	}
+	v, ok := htmlutil.RowValue(n, "System Up Time")
+	if !ok {
+		glog.V(1).Infof("No uptime on product information page")
+		return
+	}
+	d, err := modem.ParseUptime(v)
+	if err != nil {
+		glog.Warningf("Failed to parse uptime: %v", err)
+		return
+	}
+	s.Uptime = d
+}
+
+func parseStatus(r io.Reader) (*modem.Signal, error) {
+	n, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	var d map[modem.Channel]*modem.Downstream
+	var u map[modem.Channel]*modem.Upstream
+	for _, t := range cascadia.MustCompile("table").MatchAll(n) {
+		th := cascadia.MustCompile("th").MatchFirst(t)
+		if th == nil {
+			continue
+		}
+		switch htmlutil.GetText(th) {
+		case "Downstream Channel Status":
+			if d, err = parseDownstreamTable(t); err != nil {
+				return nil, err
+			}
+		case "Upstream Channel Status":
+			if u, err = parseUpstreamTable(t); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if d == nil || u == nil {
+		return nil, fmt.Errorf("Missing downstream or upstream channel status table")
+	}
+	return &modem.Signal{
+		Downstream: d,
+		Upstream:   u,
+	}, nil
+}
+
+// number parses the leading number from s, ignoring any unit suffix like
+// " Hz" or " dBmV".
+func number(s string) float64 {
+	fs := strings.Fields(s)
+	if len(fs) == 0 {
+		return 0
+	}
+	f, _ := strconv.ParseFloat(fs[0], 64)
+	return f
+}
+
+func parseDownstreamTable(n *html.Node) (map[modem.Channel]*modem.Downstream, error) {
+	m := map[modem.Channel]*modem.Downstream{}
+	rows := cascadia.MustCompile("tr").MatchAll(n)
+	if len(rows) <= 2 {
+		return nil, fmt.Errorf("Expected more than 2 rows in table, got %d", len(rows))
+	}
+	for _, row := range rows[2:] {
+		d := &modem.Downstream{}
+		var ch modem.Channel
+		var channelType string
+		for i, col := range cascadia.MustCompile("td").MatchAll(row) {
+			v := htmlutil.GetText(col)
+			switch i {
+			case 0:
+				// Channel Index
+			case 1:
+				// Channel ID
+				ch = modem.Channel(v)
+			case 2:
+				// Lock Status
+				d.Status = v
+			case 3:
+				// Channel Type
+				channelType = v
+			case 4:
+				// Bonding Status
+			case 5:
+				// Center Frequency
+				d.Frequency = strings.TrimSuffix(v, " Hz")
+			case 6:
+				// Width
+			case 7:
+				// SNR/MER Threshold Value, e.g. "40.6 dB / 24.0 dB"
+				d.SNR = number(v)
+			case 8:
+				// Receive Level
+				d.PowerLevel = number(v)
+			case 9:
+				// Modulation/Profile ID
+				d.Modulation = v
+			case 10:
+				// Unerrored Codewords
+				d.Unerrored = number(v)
+			case 11:
+				// Corrected Codewords
+				d.Correctable = number(v)
+			case 12:
+				// Uncorrectable Codewords
+				d.Uncorrectable = number(v)
+			default:
+				glog.Errorf("Unexpected %dth column in downstream table", i)
+			}
+		}
+		// OFDM channels report a list of profile IDs rather than a
+		// modulation.
+		if channelType == "OFDM" {
+			d.Modulation = channelType
+		}
+		m[ch] = d
+	}
+	return m, nil
+}
+
+func parseUpstreamTable(n *html.Node) (map[modem.Channel]*modem.Upstream, error) {
+	m := map[modem.Channel]*modem.Upstream{}
+	rows := cascadia.MustCompile("tr").MatchAll(n)
+	if len(rows) <= 2 {
+		return nil, fmt.Errorf("Expected more than 2 rows in table, got %d", len(rows))
+	}
+	for _, row := range rows[2:] {
+		u := &modem.Upstream{}
+		var ch modem.Channel
+		var channelType string
+		for i, col := range cascadia.MustCompile("td").MatchAll(row) {
+			v := htmlutil.GetText(col)
+			switch i {
+			case 0:
+				// Channel Index
+			case 1:
+				// Channel ID
+				ch = modem.Channel(v)
+			case 2:
+				// Lock Status
+				u.Status = v
+			case 3:
+				// Channel Type
+				channelType = v
+			case 4:
+				// Bonding Status
+			case 5:
+				// Center Frequency
+				u.Frequency = strings.TrimSuffix(v, " Hz")
+			case 6:
+				// Width
+			case 7:
+				// Transmit Level
+				u.PowerLevel = number(v)
+			case 8:
+				// Modulation/Profile ID
+				u.Modulation = v
+			default:
+				glog.Errorf("Unexpected %dth column in upstream table", i)
+			}
+		}
+		if channelType == "OFDMA" {
+			u.Modulation = channelType
+		}
+		m[ch] = u
+	}
+	return m, nil
+}
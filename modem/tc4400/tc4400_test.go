@@ -0,0 +1,181 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tc4400
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+)
+
+func TestParseStatus(t *testing.T) {
+	p := "testdata/TC4400.html"
+	r, err := os.Open(p)
+	if err != nil {
+		t.Fatalf("Failed to open %q: %v", p, err)
+	}
+	defer r.Close()
+
+	got, err := parseStatus(r)
+	if err != nil {
+		t.Fatalf("Failed to parse %q: %v", p, err)
+	}
+
+	want := &modem.Signal{
+		Downstream: map[modem.Channel]*modem.Downstream{
+			"1": {
+				Status:        "Locked",
+				Modulation:    "QAM256",
+				Frequency:     "591000000",
+				PowerLevel:    8.9,
+				SNR:           40.9,
+				Unerrored:     2105489212,
+				Correctable:   12,
+				Uncorrectable: 0,
+			},
+			"2": {
+				Status:        "Locked",
+				Modulation:    "QAM256",
+				Frequency:     "599000000",
+				PowerLevel:    8.7,
+				SNR:           40.6,
+				Unerrored:     2105470011,
+				Correctable:   18,
+				Uncorrectable: 0,
+			},
+			"3": {
+				Status:        "Locked",
+				Modulation:    "QAM256",
+				Frequency:     "607000000",
+				PowerLevel:    8.4,
+				SNR:           40.3,
+				Unerrored:     2105461207,
+				Correctable:   25,
+				Uncorrectable: 2,
+			},
+			"4": {
+				Status:        "Locked",
+				Modulation:    "QAM256",
+				Frequency:     "615000000",
+				PowerLevel:    8.2,
+				SNR:           40.1,
+				Unerrored:     2105452650,
+				Correctable:   31,
+				Uncorrectable: 0,
+			},
+			"33": {
+				Status:        "Locked",
+				Modulation:    "OFDM",
+				Frequency:     "738000000",
+				PowerLevel:    6.4,
+				SNR:           39,
+				Unerrored:     4271926833,
+				Correctable:   13094,
+				Uncorrectable: 0,
+			},
+		},
+		Upstream: map[modem.Channel]*modem.Upstream{
+			"1": {
+				Frequency:  "37000000",
+				PowerLevel: 44,
+				Modulation: "QAM64",
+				Status:     "Locked",
+			},
+			"2": {
+				Frequency:  "30600000",
+				PowerLevel: 44.5,
+				Modulation: "QAM64",
+				Status:     "Locked",
+			},
+			"9": {
+				Frequency:  "44000000",
+				PowerLevel: 39.5,
+				Modulation: "OFDMA",
+				Status:     "Locked",
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		g, _ := json.MarshalIndent(got, "", "  ")
+		w, _ := json.MarshalIndent(want, "", "  ")
+		t.Errorf("Got:\n%s\nWant:\n%s", g, w)
+	}
+}
+
+func TestParseStatusMissingTables(t *testing.T) {
+	if _, err := parseStatus(strings.NewReader("<html><table><tr><th>Cable Modem Status</th></tr></table></html>")); err == nil {
+		t.Errorf("Expected error parsing page without channel tables")
+	}
+}
+
+func TestFetch(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, p, _ := r.BasicAuth()
+		if u != defaultUsername || p != defaultPassword {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, "page")
+	}))
+	defer s.Close()
+	// Capturing without credentials uses the factory defaults, like Status.
+	b, err := New().(modem.Pager).Fetch(context.Background(), s.Client(), s.URL+"/cmswinfo.html")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if got, want := string(b), "page"; got != want {
+		t.Errorf("Fetch got %q want %q", got, want)
+	}
+}
+
+func TestNewFakeData(t *testing.T) {
+	for p, want := range map[string]struct {
+		uptime   time.Duration
+		firmware string
+	}{
+		// A capture holding the product information page.
+		"testdata": {2*24*time.Hour + 3*time.Hour + 4*time.Minute + 5*time.Second, "SR70.12.33-180327"},
+		// A single status page has no uptime or firmware.
+		"testdata/TC4400.html": {},
+	} {
+		m, err := NewFakeData(p)
+		if err != nil {
+			t.Fatalf("NewFakeData(%q) failed: %v", p, err)
+		}
+		s, err := m.Status(context.Background(), &http.Client{})
+		if err != nil {
+			t.Fatalf("Status of %q failed: %v", p, err)
+		}
+		if len(s.Downstream) == 0 || len(s.Upstream) == 0 {
+			t.Errorf("Status of %q has no channels", p)
+		}
+		if got := s.Uptime; got != want.uptime {
+			t.Errorf("Status of %q: Got uptime %v want %v", p, got, want.uptime)
+		}
+		if got := s.Firmware; got != want.firmware {
+			t.Errorf("Status of %q: Got firmware %q want %q", p, got, want.firmware)
+		}
+	}
+}
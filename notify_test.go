@@ -0,0 +1,89 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// listenNotify listens for notifications at the unixgram socket addr, and
+// points NOTIFY_SOCKET at it as name.  The returned func restores
+// NOTIFY_SOCKET and closes the socket.
+func listenNotify(t *testing.T, addr, name string) (*net.UnixConn, func()) {
+	c, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, ok := os.LookupEnv("NOTIFY_SOCKET")
+	os.Setenv("NOTIFY_SOCKET", name)
+	return c, func() {
+		if ok {
+			os.Setenv("NOTIFY_SOCKET", old)
+		} else {
+			os.Unsetenv("NOTIFY_SOCKET")
+		}
+		c.Close()
+	}
+}
+
+// readNotify returns the next notification sent to c.
+func readNotify(t *testing.T, c *net.UnixConn) string {
+	c.SetReadDeadline(time.Now().Add(5 * time.Second))
+	b := make([]byte, 1024)
+	n, err := c.Read(b)
+	if err != nil {
+		t.Fatalf("Failed to read notification: %v", err)
+	}
+	return string(b[:n])
+}
+
+func TestSDNotify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "notify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "notify")
+	abstract := fmt.Sprintf("surfer-test-%d", os.Getpid())
+	for _, tc := range []struct {
+		addr, name string
+	}{
+		{path, path},
+		// systemd may pass a socket in the abstract namespace.
+		{"\x00" + abstract, "@" + abstract},
+	} {
+		c, restore := listenNotify(t, tc.addr, tc.name)
+		sdNotify("READY=1")
+		if got, want := readNotify(t, c), "READY=1"; got != want {
+			t.Errorf("NOTIFY_SOCKET=%s got %q want %q", tc.name, got, want)
+		}
+		restore()
+	}
+
+	// Without NOTIFY_SOCKET, nothing is sent, nor does it fail.
+	c, restore := listenNotify(t, path+"2", "")
+	defer restore()
+	sdNotify("READY=1")
+	c.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	if n, err := c.Read(make([]byte, 1024)); err == nil {
+		t.Errorf("Got a %d byte notification without NOTIFY_SOCKET", n)
+	}
+}
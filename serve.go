@@ -0,0 +1,551 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/modem/history"
+	"github.com/wathiede/surfer/modem/store"
+	"github.com/wathiede/surfer/sink"
+)
+
+// server polls the modems and serves what it reads.  The modems, sinks and
+// poll interval come from a config that reload replaces while serving.
+type server struct {
+	ctx context.Context
+	// reg registers the prometheus metrics of each modem.
+	reg prometheus.Registerer
+	// historyCfg and storeCfg are the history settings at startup, which
+	// reload doesn't change.
+	historyCfg historyConfig
+	storeCfg   storeConfig
+
+	// reloadMu serializes reloads.
+	reloadMu sync.Mutex
+
+	// mu guards the fields below, replaced by reload.
+	mu      sync.Mutex
+	cfg     *config
+	targets []*target
+	sinks   *sink.Dispatcher
+	// ready is set once serving, and cleared on shutdown.
+	ready bool
+	// stopTicker, if not nil, stops background polling.
+	stopTicker chan struct{}
+	// polls tracks the background polling goroutine.
+	polls sync.WaitGroup
+}
+
+// Timeouts of the HTTP server.  Responses aren't bounded, as /metrics waits
+// for the modems.
+const (
+	readHeaderTimeout = 10 * time.Second
+	idleTimeout       = 2 * time.Minute
+)
+
+// newServer returns a server for cfg, registering prometheus metrics with
+// reg.  Nothing is polled until start.
+func newServer(cfg *config, reg prometheus.Registerer) *server {
+	return &server{
+		// Polls outlive the serving context, so those in flight at
+		// shutdown finish.
+		ctx:        context.Background(),
+		reg:        reg,
+		historyCfg: cfg.History,
+		storeCfg:   cfg.Store,
+		cfg:        cfg,
+	}
+}
+
+// serve exports the modems' status as prometheus metrics until ctx is done,
+// then shuts down gracefully.  It returns an error if the server can't start
+// or the listener fails.
+func serve(ctx context.Context, cfg *config) error {
+	ln, err := net.Listen("tcp", ":"+strconv.Itoa(cfg.Port))
+	if err != nil {
+		return err
+	}
+	return newServer(cfg, prometheus.DefaultRegisterer).serve(ctx, ln, http.DefaultServeMux)
+}
+
+// serve serves mux on ln, registering the server's handlers on it, until
+// ctx is done.
+func (s *server) serve(ctx context.Context, ln net.Listener, mux *http.ServeMux) error {
+	// A SIGHUP while the modems are detected is kept in the channel's
+	// buffer, and reloads once they're found.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	// Liveness and readiness are served while the modems are detected.
+	mux.HandleFunc("/-/healthy", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "Healthy")
+	})
+	mux.HandleFunc("/-/ready", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		ready := s.ready
+		s.mu.Unlock()
+		if !ready {
+			http.Error(w, "Not ready", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "Ready")
+	})
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.Serve(ln)
+	}()
+
+	if err := s.start(ctx); err != nil || ctx.Err() != nil {
+		s.shutdown(srv)
+		return err
+	}
+	s.handle(mux)
+	s.mu.Lock()
+	s.ready = true
+	s.mu.Unlock()
+	sdNotify("READY=1")
+
+	go func() {
+		for range hup {
+			if err := s.reload(); err != nil {
+				glog.Errorf("Failed to reload config: %v", err)
+			}
+		}
+	}()
+
+	var err error
+	select {
+	case <-ctx.Done():
+		glog.Infof("Shutting down")
+	case err = <-errc:
+		err = fmt.Errorf("listener returned: %v", err)
+	}
+	if serr := s.shutdown(srv); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// start detects the modems, opening their stores, sets up the sinks and
+// starts background polling.  It returns nil without doing so if ctx is
+// done before every modem is found.
+func (s *server) start(ctx context.Context) error {
+	cfg := s.cfg
+	mcs := cfg.targets()
+	clients := make([]*http.Client, len(mcs))
+	found := make([]modem.Modem, len(mcs))
+	var wg sync.WaitGroup
+	for i, mc := range mcs {
+		i, mc := i, mc
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			clients[i] = newClient(mc)
+			found[i] = detect(ctx, clients[i], mc, cfg.Timeout)
+		}()
+	}
+	wg.Wait()
+	for _, m := range found {
+		if m == nil {
+			return nil
+		}
+	}
+
+	var targets []*target
+	for i, mc := range mcs {
+		t, err := newTarget(mc, clients[i], found[i], s.historyCfg, s.storeCfg)
+		if err != nil {
+			closeTargets(targets)
+			return err
+		}
+		targets = append(targets, t)
+	}
+	if cfg.Sinks.Prometheus {
+		for _, t := range targets {
+			if err := t.register(s.reg); err != nil {
+				closeTargets(targets)
+				return fmt.Errorf("failed to set up sinks: %v", err)
+			}
+		}
+	}
+	sinks, err := newSinks(cfg, sinkModel(found))
+	if err != nil {
+		closeTargets(targets)
+		return fmt.Errorf("failed to set up sinks: %v", err)
+	}
+	s.mu.Lock()
+	s.targets, s.sinks = targets, sinks
+	s.startTicker()
+	s.mu.Unlock()
+	return nil
+}
+
+// handle registers the handlers serving the modems on mux.  Those of a
+// single modem serve the one named by the "modem" query parameter, or the
+// first.
+func (s *server) handle(mux *http.ServeMux) {
+	// Refresh data every prometheus poll.
+	ph := promhttp.Handler()
+	mux.Handle("/metrics", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.config().Sinks.Prometheus {
+			http.NotFound(w, r)
+			return
+		}
+		if err := s.pollAll(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		ph.ServeHTTP(w, r)
+	}))
+	mux.HandleFunc("/-/reload", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "Reload requires POST", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := s.reload(); err != nil {
+			glog.Errorf("Failed to reload config: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintln(w, "Reloaded")
+	})
+	mux.Handle("/api/v1/channel_events", s.modemHandler(func(t *target) http.Handler {
+		return t.channelEvents
+	}))
+	mux.Handle("/api/v1/status", s.modemHandler(func(t *target) http.Handler {
+		return t.latest.handler(func() error { return s.poll(t) })
+	}))
+	mux.Handle("/api/v1/history", s.modemHandler(func(t *target) http.Handler {
+		if st := t.historyStore(); st != nil {
+			return history.Handler(st)
+		}
+		return history.Handler(t.samples)
+	}))
+	mux.Handle("/api/v1/events", s.modemHandler(func(t *target) http.Handler {
+		if st := t.historyStore(); st != nil {
+			return store.EventsHandler(st)
+		}
+		return http.NotFoundHandler()
+	}))
+	mux.Handle("/", dashboard())
+}
+
+// modemHandler serves each request with the handler h returns for the modem
+// named by the request's "modem" query parameter, or the first.
+func (s *server) modemHandler(h func(t *target) http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("modem")
+		t := s.target(name)
+		if t == nil {
+			http.Error(w, fmt.Sprintf("No modem named %q", name), http.StatusNotFound)
+			return
+		}
+		h(t).ServeHTTP(w, r)
+	})
+}
+
+// target returns the modem named name, or the first if name is empty.  It
+// returns nil if there's no such modem.
+func (s *server) target(name string) *target {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.targets {
+		if name == "" || t.name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// shutdown stops serving, waiting for requests in flight, then stops
+// polling, flushes the sinks and closes the stores.  Waiting for requests
+// and flushing are bounded by the shutdown timeout together; results still
+// queued when it expires are dropped.
+func (s *server) shutdown(srv *http.Server) error {
+	sdNotify("STOPPING=1")
+	s.mu.Lock()
+	s.ready = false
+	timeout := s.cfg.ShutdownTimeout
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		glog.Warningf("Requests still in flight after %s: %v", timeout, err)
+	}
+	// Wait for a reload in progress, and keep new ones from starting.
+	s.reloadMu.Lock()
+	s.mu.Lock()
+	s.stopPolling()
+	sinks, targets := s.sinks, s.targets
+	s.mu.Unlock()
+	s.polls.Wait()
+
+	var first error
+	if sinks != nil {
+		first = sinks.Close(ctx)
+	}
+	if err := closeTargets(targets); first == nil {
+		first = err
+	}
+	return first
+}
+
+// closeTargets closes the stores of targets, returning the first error.
+func closeTargets(targets []*target) error {
+	var first error
+	for _, t := range targets {
+		if err := t.close(); err != nil {
+			glog.Errorf("Failed to close store: %v", err)
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
+
+// sinkModel returns the model of the modem polled, or "" if there are
+// several, for newSinks.
+func sinkModel(found []modem.Modem) string {
+	if len(found) != 1 {
+		return ""
+	}
+	return found[0].Name()
+}
+
+// config returns the config in effect.
+func (s *server) config() *config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cfg
+}
+
+// poll polls t, sending the result to the sinks in effect.
+func (s *server) poll(t *target) error {
+	s.mu.Lock()
+	cfg, sinks := s.cfg, s.sinks
+	s.mu.Unlock()
+	return t.poll(s.ctx, cfg, sinks)
+}
+
+// pollAll polls every modem at once.  Failures are logged when there are
+// several modems, and returned only if every poll fails.
+func (s *server) pollAll() error {
+	s.mu.Lock()
+	targets := s.targets
+	s.mu.Unlock()
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		i, t := i, t
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = s.poll(t)
+		}()
+	}
+	wg.Wait()
+	var first error
+	failed := 0
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if first == nil {
+			first = err
+		}
+		failed++
+		if len(targets) > 1 {
+			glog.Warningf("Failed to poll modem %q: %v", targets[i].name, err)
+		}
+	}
+	if failed < len(targets) {
+		return nil
+	}
+	return first
+}
+
+// startTicker polls every s.cfg.PollInterval in the background, stopping
+// any previous ticker.  s.mu must be held.
+func (s *server) startTicker() {
+	s.stopPolling()
+	d := s.cfg.PollInterval
+	if d <= 0 {
+		return
+	}
+	stop := make(chan struct{})
+	s.stopTicker = stop
+	s.polls.Add(1)
+	go func() {
+		defer s.polls.Done()
+		t := time.NewTicker(d)
+		defer t.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-t.C:
+				if err := s.pollAll(); err != nil {
+					glog.Errorf("Failed to poll modem: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// stopPolling stops background polling, if started.  s.mu must be held.
+func (s *server) stopPolling() {
+	if s.stopTicker != nil {
+		close(s.stopTicker)
+		s.stopTicker = nil
+	}
+}
+
+// reload reads the config again and applies it: finding modems again if
+// their settings changed, adding and removing modems, and replacing the
+// sinks and background polling.  Modems keep their history and metrics
+// while their name is unchanged.  If the config is invalid or a modem can't
+// be found, the previous config stays in effect.  The port, history and
+// store can't change while serving, nor can an unnamed modem be named.
+func (s *server) reload() error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	old, targets := s.cfg, s.targets
+	s.mu.Unlock()
+	if cfg.Port != old.Port || cfg.History != old.History || cfg.Store != old.Store {
+		glog.Warningf("Changes to port, history and store take effect on restart")
+	}
+	// Named modems' metrics have a modem label, and a registry can't
+	// change a metric's labels.
+	if (old.targets()[0].Name == "") != (cfg.targets()[0].Name == "") {
+		return fmt.Errorf("naming the modem, or removing its name, takes effect on restart")
+	}
+
+	current := map[string]*target{}
+	for _, t := range targets {
+		current[t.name] = t
+	}
+	type update struct {
+		t      *target
+		mc     modemConfig
+		client *http.Client
+		m      modem.Modem
+	}
+	var (
+		next    []*target
+		found   []modem.Modem
+		updates []update
+		added   []*target
+	)
+	for _, mc := range cfg.targets() {
+		t := current[mc.Name]
+		if t != nil && t.config() == mc && cfg.Timeout == old.Timeout {
+			t.mu.Lock()
+			found = append(found, t.modem)
+			t.mu.Unlock()
+			next = append(next, t)
+			continue
+		}
+		client := newClient(mc)
+		m := detectOnce(s.ctx, client, mc, cfg.Timeout)
+		if m == nil {
+			closeTargets(added)
+			return fmt.Errorf("failed to find modem%s", describe(mc))
+		}
+		if t == nil {
+			if t, err = newTarget(mc, client, m, s.historyCfg, s.storeCfg); err != nil {
+				closeTargets(added)
+				return err
+			}
+			added = append(added, t)
+		} else {
+			glog.Infof("Found modem %q", m.Name())
+			updates = append(updates, update{t, mc, client, m})
+		}
+		next = append(next, t)
+		found = append(found, m)
+	}
+	sinks, err := newSinks(cfg, sinkModel(found))
+	if err != nil {
+		closeTargets(added)
+		return err
+	}
+
+	s.mu.Lock()
+	oldSinks := s.sinks
+	s.cfg, s.targets, s.sinks = cfg, next, sinks
+	for _, u := range updates {
+		u.t.set(u.mc, u.client, u.m)
+	}
+	if cfg.PollInterval != old.PollInterval {
+		s.startTicker()
+	}
+	s.mu.Unlock()
+
+	kept := map[*target]bool{}
+	for _, t := range next {
+		kept[t] = true
+	}
+	var removed []*target
+	for _, t := range targets {
+		if !kept[t] {
+			t.unregister(s.reg)
+			removed = append(removed, t)
+		}
+	}
+	closeTargets(removed)
+	if cfg.Sinks.Prometheus {
+		for _, t := range next {
+			if err := t.register(s.reg); err != nil {
+				glog.Errorf("Failed to register metrics of modem %q: %v", t.name, err)
+			}
+		}
+	}
+	// Queued results are written before the old sinks are closed, unless
+	// that takes longer than a write may.
+	ctx, cancel := context.WithTimeout(context.Background(), sinkTimeout)
+	defer cancel()
+	oldSinks.Close(ctx)
+	glog.Infof("Reloaded config")
+	return nil
+}
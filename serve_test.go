@@ -0,0 +1,468 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/wathiede/surfer/modem/store"
+)
+
+const (
+	sb8200Fake = "modem/sb8200/testdata"
+	sb6183Fake = "modem/sb6183/testdata"
+)
+
+// testServer is a server reading its config from a file in a temporary
+// directory, as reload reads -config.
+type testServer struct {
+	*server
+	t    *testing.T
+	dir  string
+	path string
+	reg  *prometheus.Registry
+	old  string
+}
+
+// startServer writes config and starts a server for it.
+func startServer(t *testing.T, config string) *testServer {
+	dir, err := ioutil.TempDir("", "serve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := &testServer{t: t, dir: dir, path: filepath.Join(dir, "surfer.yaml"), reg: prometheus.NewRegistry(), old: *configPath}
+	*configPath = ts.path
+	ts.write(config)
+	cfg, err := loadConfig(ts.path)
+	if err != nil {
+		ts.close()
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	ts.server = newServer(cfg, ts.reg)
+	if err := ts.start(context.Background()); err != nil {
+		ts.close()
+		t.Fatalf("start failed: %v", err)
+	}
+	return ts
+}
+
+// write replaces the config, with {{dir}} replaced by the temporary
+// directory.
+func (ts *testServer) write(config string) {
+	config = strings.Replace(config, "{{dir}}", ts.dir, -1)
+	if err := ioutil.WriteFile(ts.path, []byte(config), 0644); err != nil {
+		ts.t.Fatal(err)
+	}
+}
+
+func (ts *testServer) close() {
+	if ts.server != nil {
+		if err := ts.shutdown(&http.Server{}); err != nil {
+			ts.t.Errorf("shutdown failed: %v", err)
+		}
+	}
+	*configPath = ts.old
+	os.RemoveAll(ts.dir)
+}
+
+// names returns the names of the modems polled.
+func (ts *testServer) names() []string {
+	var names []string
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	for _, t := range ts.targets {
+		names = append(names, t.name)
+	}
+	return names
+}
+
+func TestReloadInvalid(t *testing.T) {
+	ts := startServer(t, "modem: {fake: "+sb8200Fake+"}\n")
+	defer ts.close()
+	cfg := ts.config()
+	for _, config := range []string{
+		"port: 0\n",
+		"modem: {fake: /nonexistent}\n",
+		"modems: [{name: a, fake: " + sb8200Fake + "}, {name: b, fake: /nonexistent}]\n",
+	} {
+		ts.write(config)
+		if err := ts.reload(); err == nil {
+			t.Errorf("reload of %q succeeded", config)
+		}
+		if got := ts.config(); got != cfg {
+			t.Errorf("reload of %q replaced the config", config)
+		}
+		if got, want := ts.names(), []string{""}; !reflect.DeepEqual(got, want) {
+			t.Errorf("reload of %q: got modems %q want %q", config, got, want)
+		}
+	}
+	if err := ts.pollAll(); err != nil {
+		t.Errorf("poll after failed reloads failed: %v", err)
+	}
+}
+
+// lines returns the lines of the file at path.
+func lines(t *testing.T, path string) []string {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(b)), "\n")
+}
+
+func TestReloadSinks(t *testing.T) {
+	ts := startServer(t, "modem: {fake: "+sb8200Fake+"}\nsinks: {json_log: {{dir}}/a.log}\n")
+	defer ts.close()
+	if err := ts.pollAll(); err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	old := ts.sinks
+	ts.write("modem: {fake: " + sb8200Fake + "}\nsinks: {json_log: {{dir}}/b.log}\n")
+	if err := ts.reload(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if ts.sinks == old {
+		t.Errorf("reload kept the sinks")
+	}
+	if err := ts.pollAll(); err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	ts.shutdown(&http.Server{})
+	ts.server = nil
+
+	// The old sinks were flushed and closed by the reload, and the poll
+	// after it went to the new ones only.
+	for _, name := range []string{"a.log", "b.log"} {
+		l := lines(t, filepath.Join(ts.dir, name))
+		if len(l) != 1 {
+			t.Errorf("Got %d lines in %s, want 1", len(l), name)
+			continue
+		}
+		var r struct{ Modem string }
+		if err := json.Unmarshal([]byte(l[0]), &r); err != nil || r.Modem != "SB8200" {
+			t.Errorf("Got %s in %s, want a result from the SB8200: %v", l[0], name, err)
+		}
+	}
+}
+
+func TestReloadPollInterval(t *testing.T) {
+	ts := startServer(t, "modem: {fake: "+sb8200Fake+"}\n")
+	defer ts.close()
+	samples := func() int {
+		s, err := ts.target("").samples.Range(time.Time{}, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(s)
+	}
+	if ts.stopTicker != nil {
+		t.Fatalf("Polling in the background without poll_interval")
+	}
+
+	ts.write("modem: {fake: " + sb8200Fake + "}\npoll_interval: 10ms\n")
+	if err := ts.reload(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for samples() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Got %d samples after 5s polling every 10ms, want at least 2", samples())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	ts.write("modem: {fake: " + sb8200Fake + "}\n")
+	if err := ts.reload(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	ts.mu.Lock()
+	stopped := ts.stopTicker == nil
+	ts.mu.Unlock()
+	if !stopped {
+		t.Errorf("Still polling in the background after poll_interval was removed")
+	}
+}
+
+// modemLabels returns the values of the modem label of the downstream_snr
+// series in reg, and whether any series has no modem label.
+func modemLabels(t *testing.T, reg *prometheus.Registry) ([]string, bool) {
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{}
+	var got []string
+	unlabeled := false
+	for _, mf := range mfs {
+		if mf.GetName() != "downstream_snr" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			found := false
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "modem" {
+					found = true
+					if !seen[l.GetValue()] {
+						seen[l.GetValue()] = true
+						got = append(got, l.GetValue())
+					}
+				}
+			}
+			if !found {
+				unlabeled = true
+			}
+		}
+	}
+	return got, unlabeled
+}
+
+func TestReloadModems(t *testing.T) {
+	ts := startServer(t, "modems: [{name: a, fake: "+sb8200Fake+"}]\nstore: {dir: {{dir}}/store}\n")
+	defer ts.close()
+
+	ts.write("modems: [{name: a, fake: " + sb8200Fake + "}, {name: b, fake: " + sb6183Fake + "}]\nstore: {dir: {{dir}}/store}\n")
+	if err := ts.reload(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if got, want := ts.names(), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got modems %q want %q", got, want)
+	}
+	if err := ts.pollAll(); err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	got, unlabeled := modemLabels(t, ts.reg)
+	if want := []string{"a", "b"}; !reflect.DeepEqual(got, want) || unlabeled {
+		t.Errorf("Got modem labels %q, unlabeled series %t, want %q and none", got, unlabeled, want)
+	}
+	for _, name := range []string{"a", "b"} {
+		if _, err := os.Stat(filepath.Join(ts.dir, "store", name, "store.db")); err != nil {
+			t.Errorf("Store of modem %q: %v", name, err)
+		}
+	}
+
+	// Removing a modem drops its metrics.
+	ts.write("modems: [{name: a, fake: " + sb8200Fake + "}]\nstore: {dir: {{dir}}/store}\n")
+	if err := ts.reload(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if got, want := ts.names(), []string{"a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got modems %q want %q", got, want)
+	}
+	if got, _ := modemLabels(t, ts.reg); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("Got modem labels %q after removing b, want [a]", got)
+	}
+
+	// The metrics of an unnamed modem have no modem label, so it can't
+	// replace named ones.
+	ts.write("modem: {fake: " + sb8200Fake + "}\nstore: {dir: {{dir}}/store}\n")
+	if err := ts.reload(); err == nil || !strings.Contains(err.Error(), "takes effect on restart") {
+		t.Errorf("reload to an unnamed modem got error %v, want one taking effect on restart", err)
+	}
+}
+
+func TestUnnamedModemMetrics(t *testing.T) {
+	ts := startServer(t, "modem: {fake: "+sb8200Fake+"}\n")
+	defer ts.close()
+	if err := ts.pollAll(); err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	if got, unlabeled := modemLabels(t, ts.reg); len(got) != 0 || !unlabeled {
+		t.Errorf("Got modem labels %q, want none for an unnamed modem", got)
+	}
+}
+
+func TestHandleModems(t *testing.T) {
+	ts := startServer(t, "modems: [{name: a, fake: "+sb8200Fake+"}, {name: b, fake: "+sb6183Fake+"}]\n")
+	defer ts.close()
+	mux := http.NewServeMux()
+	ts.handle(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		query string
+		code  int
+		modem string
+	}{
+		{"", http.StatusOK, "a"},
+		{"?modem=a", http.StatusOK, "a"},
+		{"?modem=b", http.StatusOK, "b"},
+		{"?modem=c", http.StatusNotFound, ""},
+	} {
+		resp, err := http.Get(srv.URL + "/api/v1/status" + tc.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var b bytes.Buffer
+		b.ReadFrom(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != tc.code {
+			t.Errorf("GET /api/v1/status%s got %s, want %d: %s", tc.query, resp.Status, tc.code, b.String())
+			continue
+		}
+		if tc.code != http.StatusOK {
+			continue
+		}
+		var r struct{ Modem string }
+		if err := json.Unmarshal(b.Bytes(), &r); err != nil {
+			t.Fatal(err)
+		}
+		if r.Modem != tc.modem {
+			t.Errorf("GET /api/v1/status%s got modem %q, want %q", tc.query, r.Modem, tc.modem)
+		}
+	}
+}
+
+func TestServe(t *testing.T) {
+	dir, err := ioutil.TempDir("", "serve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	nc, restore := listenNotify(t, filepath.Join(dir, "notify"), filepath.Join(dir, "notify"))
+	defer restore()
+	defer func(d time.Duration) { detectRetry = d }(detectRetry)
+	detectRetry = 10 * time.Millisecond
+	defer func(p string) { *configPath = p }(*configPath)
+	*configPath = filepath.Join(dir, "surfer.yaml")
+	// The modem is found once the test data is moved to fake.
+	fake := filepath.Join(dir, "fake")
+	config := "modem: {fake: " + fake + "}\nstore: {dir: " + dir + "/store}\nsinks: {json_log: " + dir + "/surfer.log}\n"
+	if err := ioutil.WriteFile(*configPath, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+
+	s := newServer(cfg, prometheus.NewRegistry())
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := "http://" + ln.Addr().String()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.serve(ctx, ln, http.NewServeMux())
+	}()
+	get := func(path string) int {
+		resp, err := http.Get(base + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// While the modem is detected, surfer is alive but not ready, and a
+	// SIGHUP reloads once it's ready rather than killing it.
+	if got := get("/-/healthy"); got != http.StatusOK {
+		t.Errorf("/-/healthy while detecting got %d, want %d", got, http.StatusOK)
+	}
+	if got := get("/-/ready"); got != http.StatusServiceUnavailable {
+		t.Errorf("/-/ready while detecting got %d, want %d", got, http.StatusServiceUnavailable)
+	}
+	if err := ioutil.WriteFile(*configPath, []byte(config+"poll_interval: 1h\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	staging := filepath.Join(dir, "staging")
+	if err := os.Mkdir(staging, 0755); err != nil {
+		t.Fatal(err)
+	}
+	fis, err := ioutil.ReadDir(sb8200Fake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fi := range fis {
+		b, err := ioutil.ReadFile(filepath.Join(sb8200Fake, fi.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(staging, fi.Name()), b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Rename(staging, fake); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := readNotify(t, nc), "READY=1"; got != want {
+		t.Fatalf("Got notification %q want %q", got, want)
+	}
+	if got := get("/-/ready"); got != http.StatusOK {
+		t.Errorf("/-/ready once found got %d, want %d", got, http.StatusOK)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for s.config().PollInterval != time.Hour {
+		if time.Now().After(deadline) {
+			t.Fatalf("SIGHUP while detecting wasn't reloaded once ready")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := get("/metrics"); got != http.StatusOK {
+		t.Errorf("/metrics got %d, want %d", got, http.StatusOK)
+	}
+
+	cancel()
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Errorf("serve failed: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("serve didn't return 10s after cancelling")
+	}
+	if got, want := readNotify(t, nc), "STOPPING=1"; got != want {
+		t.Errorf("Got notification %q want %q", got, want)
+	}
+	// By the time serve returns, the result of the scrape is flushed to
+	// the log and the store is closed.
+	if l := lines(t, filepath.Join(dir, "surfer.log")); len(l) != 1 {
+		t.Errorf("Got %d lines in the log, want 1", len(l))
+	}
+	st, err := store.Open(filepath.Join(dir, "store"), time.Hour)
+	if err != nil {
+		t.Fatalf("Store still open after serve returned: %v", err)
+	}
+	defer st.Close()
+	records, err := st.Records(time.Now().Add(-time.Hour), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) == 0 {
+		t.Errorf("Got no records in the store, want the scrape's")
+	}
+}
@@ -0,0 +1,345 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package file appends the result of each poll to a file, as JSON Lines or
+// CSV, rotating it by size and age and compressing the rotated files.
+package file
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/wathiede/surfer/modem/health"
+	"github.com/wathiede/surfer/sink"
+	"github.com/wathiede/surfer/sink/jsonlog"
+)
+
+// Format is how results are written.
+type Format string
+
+const (
+	// JSONLines writes a jsonlog.Record per line.
+	JSONLines Format = "jsonl"
+	// CSV writes a header row, then a row per channel.
+	CSV Format = "csv"
+)
+
+// Options configure a Sink.
+type Options struct {
+	// Path is the file written to, e.g. "/var/log/surfer/signal.csv".
+	Path   string
+	Format Format
+	// MaxSize rotates the file before it grows beyond this many bytes.  0
+	// means no limit.
+	MaxSize int64
+	// RotateEvery rotates the file when a result falls in a different
+	// period than the last one written, e.g. at midnight UTC for 24h.  0
+	// disables rotation by time.
+	RotateEvery time.Duration
+	// MaxBackups is the number of rotated files kept, 0 for all.
+	MaxBackups int
+	// Compress gzips rotated files.
+	Compress bool
+}
+
+// Sink writes results to a file.  It isn't safe for concurrent use, which a
+// sink.Dispatcher ensures.
+type Sink struct {
+	o    Options
+	f    *os.File
+	size int64
+	// last is when the last result in f was written.
+	last time.Time
+}
+
+// New opens o.Path for appending, creating it if needed.
+func New(o Options) (*Sink, error) {
+	if o.Format != JSONLines && o.Format != CSV {
+		return nil, fmt.Errorf("unknown format %q", o.Format)
+	}
+	s := &Sink{o: o}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Sink) open() error {
+	if err := os.MkdirAll(filepath.Dir(s.o.Path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.o.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	s.f = f
+	s.size = fi.Size()
+	s.last = time.Time{}
+	if s.size > 0 {
+		s.last = fi.ModTime()
+	}
+	return nil
+}
+
+// Write appends r, rotating the file first if needed.
+func (s *Sink) Write(_ context.Context, r *sink.Result) error {
+	if s.f == nil {
+		// Starting a new file failed when rotating.
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+	b, err := s.encode(r)
+	if err != nil {
+		return err
+	}
+	if s.rotateBefore(r.Time, len(b)) {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+		// A new CSV file needs a header.
+		if b, err = s.encode(r); err != nil {
+			return err
+		}
+	}
+	n, err := s.f.Write(b)
+	s.size += int64(n)
+	s.last = r.Time
+	return err
+}
+
+// rotateBefore reports whether the file should be rotated before writing n
+// bytes at t.
+func (s *Sink) rotateBefore(t time.Time, n int) bool {
+	if s.size == 0 {
+		return false
+	}
+	if s.o.MaxSize > 0 && s.size+int64(n) > s.o.MaxSize {
+		return true
+	}
+	return s.o.RotateEvery > 0 && !s.last.Truncate(s.o.RotateEvery).Equal(t.Truncate(s.o.RotateEvery))
+}
+
+// stampFormat is the time stamp in rotated file names.
+const stampFormat = "20060102T150405"
+
+// rotate renames the file after the time of its last result and starts a new
+// file, then compresses the rotated file and removes the oldest ones.  Only
+// failing to start the new file is an error, as results can still be written
+// when the rest fails.
+func (s *Sink) rotate() error {
+	if err := s.f.Close(); err != nil {
+		glog.Errorf("Failed to close %s: %v", s.o.Path, err)
+	}
+	s.f = nil
+	ext := filepath.Ext(s.o.Path)
+	base := strings.TrimSuffix(s.o.Path, ext)
+	stamp := s.last.UTC().Format(stampFormat)
+	name := fmt.Sprintf("%s-%s%s", base, stamp, ext)
+	for i := 1; exists(name) || exists(name+".gz"); i++ {
+		name = fmt.Sprintf("%s-%s.%d%s", base, stamp, i, ext)
+	}
+	if err := os.Rename(s.o.Path, name); err != nil {
+		// Keep appending to the unrotated file.
+		glog.Errorf("Failed to rotate %s: %v", s.o.Path, err)
+		return s.open()
+	}
+	if err := s.open(); err != nil {
+		return err
+	}
+	if s.o.Compress {
+		if err := compressFile(name); err != nil {
+			glog.Errorf("Failed to compress %s, leaving it uncompressed: %v", name, err)
+		}
+	}
+	if s.o.MaxBackups > 0 {
+		if err := prune(base, ext, s.o.MaxBackups); err != nil {
+			glog.Errorf("Failed to remove old rotated files of %s: %v", s.o.Path, err)
+		}
+	}
+	return nil
+}
+
+func exists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+// compressFile compresses rotated files.  It is a variable so tests can make
+// it fail.
+var compressFile = compress
+
+// compress replaces name with a gzipped name.gz.
+func compress(name string) error {
+	in, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(name+".gz", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		out.Close()
+		os.Remove(out.Name())
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		os.Remove(out.Name())
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(out.Name())
+		return err
+	}
+	return os.Remove(name)
+}
+
+// prune removes all but the newest keep files rotated from base+ext.
+func prune(base, ext string, keep int) error {
+	names, err := filepath.Glob(base + "-*" + ext + "*")
+	if err != nil {
+		return err
+	}
+	prefix := filepath.Base(base) + "-"
+	sort.Slice(names, func(i, j int) bool {
+		si, ni := backupOrder(names[i], prefix)
+		sj, nj := backupOrder(names[j], prefix)
+		if si != sj {
+			return si < sj
+		}
+		return ni < nj
+	})
+	for len(names) > keep {
+		if err := os.Remove(names[0]); err != nil {
+			return err
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// backupOrder returns what a rotated file name sorts by, oldest first: its
+// time stamp, then the number added when several files share the stamp, so
+// name-stamp.1.ext is newer than name-stamp.ext.
+func backupOrder(name, prefix string) (string, int) {
+	rest := strings.TrimPrefix(filepath.Base(name), prefix)
+	if len(rest) < len(stampFormat) {
+		return rest, 0
+	}
+	stamp, rest := rest[:len(stampFormat)], rest[len(stampFormat):]
+	n := 0
+	if strings.HasPrefix(rest, ".") {
+		n, _ = strconv.Atoi(strings.SplitN(rest[1:], ".", 2)[0])
+	}
+	return stamp, n
+}
+
+// Close closes the file.
+func (s *Sink) Close() error {
+	if s.f == nil {
+		return nil
+	}
+	return s.f.Close()
+}
+
+// csvHeader names the CSV columns.
+var csvHeader = []string{
+	"time", "modem", "direction", "channel", "frequency", "modulation", "status",
+	"power_level", "snr", "unerrored", "correctable", "uncorrectable", "symbol_rate",
+	"health", "error",
+}
+
+func (s *Sink) encode(r *sink.Result) ([]byte, error) {
+	var b bytes.Buffer
+	if s.o.Format == JSONLines {
+		err := json.NewEncoder(&b).Encode(jsonlog.NewRecord(r))
+		return b.Bytes(), err
+	}
+	w := csv.NewWriter(&b)
+	if s.size == 0 {
+		w.Write(csvHeader)
+	}
+	for _, row := range rows(r) {
+		w.Write(row)
+	}
+	w.Flush()
+	return b.Bytes(), w.Error()
+}
+
+func float(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// rows returns the CSV rows for r: one per channel, or one with just the
+// error for a failed poll.
+func rows(r *sink.Result) [][]string {
+	t := r.Time.UTC().Format(time.RFC3339)
+	if r.Err != nil {
+		return [][]string{{t, r.Modem, "", "", "", "", "", "", "", "", "", "", "", "", r.Err.Error()}}
+	}
+	grade := func(c *health.ChannelReport) string {
+		if c == nil {
+			return ""
+		}
+		return c.Grade.String()
+	}
+	var rows [][]string
+	for _, ch := range r.Signal.DownstreamChannels() {
+		d := r.Signal.Downstream[ch]
+		var c *health.ChannelReport
+		if r.Health != nil {
+			c = r.Health.Downstream[ch]
+		}
+		rows = append(rows, []string{
+			t, r.Modem, "downstream", string(ch), d.Frequency, d.Modulation, d.Status,
+			float(d.PowerLevel), float(d.SNR), float(d.Unerrored), float(d.Correctable), float(d.Uncorrectable), "",
+			grade(c), "",
+		})
+	}
+	for _, ch := range r.Signal.UpstreamChannels() {
+		u := r.Signal.Upstream[ch]
+		var c *health.ChannelReport
+		if r.Health != nil {
+			c = r.Health.Upstream[ch]
+		}
+		rows = append(rows, []string{
+			t, r.Modem, "upstream", string(ch), u.Frequency, u.Modulation, u.Status,
+			float(u.PowerLevel), "", "", "", "", float(u.SymbolRate),
+			grade(c), "",
+		})
+	}
+	return rows
+}
@@ -0,0 +1,254 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/modem/health"
+	"github.com/wathiede/surfer/sink"
+)
+
+var _ sink.Sink = &Sink{}
+
+var signal = &modem.Signal{
+	Downstream: map[modem.Channel]*modem.Downstream{
+		"1": {Frequency: "591000000", Modulation: "QAM256", PowerLevel: 1.5, SNR: 40.1, Correctable: 12},
+	},
+	Upstream: map[modem.Channel]*modem.Upstream{
+		"2": {Frequency: "35600000", Modulation: "ATDMA", Status: "Locked", PowerLevel: 44, SymbolRate: 5120},
+	},
+}
+
+func result(t time.Time) *sink.Result {
+	return &sink.Result{Modem: "SB8200", Time: t, Signal: signal, Health: health.DOCSIS().Evaluate(signal)}
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func read(t *testing.T, name string) string {
+	t.Helper()
+	if strings.HasSuffix(name, ".gz") {
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(zr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func ls(t *testing.T, dir string) []string {
+	t.Helper()
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, fi := range fis {
+		names = append(names, fi.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestCSV(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "signal.csv")
+	t0 := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	s, err := New(Options{Path: path, Format: CSV})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := s.Write(context.Background(), result(t0)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	s.Close()
+	// Reopening appends without another header.
+	if s, err = New(Options{Path: path, Format: CSV}); err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := s.Write(context.Background(), &sink.Result{Modem: "SB8200", Time: t0.Add(time.Minute), Err: errors.New("timeout, no response")}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	s.Close()
+
+	want := `time,modem,direction,channel,frequency,modulation,status,power_level,snr,unerrored,correctable,uncorrectable,symbol_rate,health,error
+2026-01-02T03:04:05Z,SB8200,downstream,1,591000000,QAM256,,1.5,40.1,0,12,0,,good,
+2026-01-02T03:04:05Z,SB8200,upstream,2,35600000,ATDMA,Locked,44,,,,,5120,good,
+2026-01-02T03:05:05Z,SB8200,,,,,,,,,,,,,"timeout, no response"
+`
+	if got := read(t, path); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "signal.jsonl")
+	t0 := time.Date(2026, 1, 2, 22, 0, 0, 0, time.UTC)
+
+	s, err := New(Options{Path: path, Format: JSONLines, RotateEvery: 24 * time.Hour, MaxBackups: 2, Compress: true})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer s.Close()
+	// Hourly results from 22:00 on the 2nd to 01:00 on the 6th rotate at
+	// each midnight, keeping the 4th and 5th.
+	for i := 0; i < 3*24+4; i++ {
+		if err := s.Write(context.Background(), result(t0.Add(time.Duration(i)*time.Hour))); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if got, want := ls(t, dir), []string{
+		"signal-20260104T230000.jsonl.gz",
+		"signal-20260105T230000.jsonl.gz",
+		"signal.jsonl",
+	}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Files got %q want %q", got, want)
+	}
+	if got := strings.Count(read(t, filepath.Join(dir, "signal-20260105T230000.jsonl.gz")), "\n"); got != 24 {
+		t.Errorf("Rotated file has %d lines, want 24", got)
+	}
+	if got := strings.Count(read(t, path), "\n"); got != 2 {
+		t.Errorf("Current file has %d lines, want 2", got)
+	}
+}
+
+func TestRotateSize(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "signal.csv")
+	t0 := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// Room for the header and two results.
+	s, err := New(Options{Path: path, Format: CSV, MaxSize: 500})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer s.Close()
+	for i := 0; i < 5; i++ {
+		if err := s.Write(context.Background(), result(t0)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if got, want := ls(t, dir), []string{
+		"signal-20260102T030405.1.csv",
+		"signal-20260102T030405.csv",
+		"signal.csv",
+	}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Files got %q want %q", got, want)
+	}
+	for _, name := range ls(t, dir) {
+		got := read(t, filepath.Join(dir, name))
+		if len(got) > 500 {
+			t.Errorf("%s has %d bytes, more than 500", name, len(got))
+		}
+		if !strings.HasPrefix(got, "time,modem,") {
+			t.Errorf("%s is missing the CSV header", name)
+		}
+	}
+}
+
+func TestRotateCompressFails(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "signal.jsonl")
+	t0 := time.Date(2026, 1, 2, 22, 0, 0, 0, time.UTC)
+	defer func() { compressFile = compress }()
+	compressFile = func(string) error { return errors.New("disk full") }
+
+	s, err := New(Options{Path: path, Format: JSONLines, RotateEvery: 24 * time.Hour, Compress: true})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer s.Close()
+	// Failing to compress the file rotated at midnight leaves it
+	// uncompressed, and writing goes on in a new file.
+	for i := 0; i < 4; i++ {
+		if err := s.Write(context.Background(), result(t0.Add(time.Duration(i)*time.Hour))); err != nil {
+			t.Fatalf("Write %d failed: %v", i, err)
+		}
+	}
+	if got, want := ls(t, dir), []string{
+		"signal-20260102T230000.jsonl",
+		"signal.jsonl",
+	}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Files got %q want %q", got, want)
+	}
+	if got := strings.Count(read(t, path), "\n"); got != 2 {
+		t.Errorf("Current file has %d lines, want 2", got)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	for _, name := range []string{
+		"signal-20260102T030405.csv.gz",
+		"signal-20260102T030405.1.csv.gz",
+		"signal-20260102T030405.2.csv",
+		"signal-20260103T030405.csv.gz",
+		"signal.csv",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := prune(filepath.Join(dir, "signal"), ".csv", 2); err != nil {
+		t.Fatalf("prune failed: %v", err)
+	}
+	// Files sharing a stamp are numbered oldest first.
+	if got, want := ls(t, dir), []string{
+		"signal-20260102T030405.2.csv",
+		"signal-20260103T030405.csv.gz",
+		"signal.csv",
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Files got %q want %q", got, want)
+	}
+}
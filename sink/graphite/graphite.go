@@ -0,0 +1,178 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package graphite sends a modem.Signal to Graphite, using the plaintext
+// protocol, or to StatsD as gauges.
+//
+// Channel labels are flattened into dotted paths, so with the default
+// prefix the SNR of downstream channel 3 is surfer.downstream.3.snr.  With
+// PerModem, the modem's name follows the prefix, e.g.
+// surfer.upstairs.downstream.3.snr.
+package graphite
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/sink"
+)
+
+// Metric is a value at a dotted path.
+type Metric struct {
+	Path  string
+	Value float64
+}
+
+var componentEscaper = strings.NewReplacer(".", "_", " ", "_", "/", "_", ":", "_", "|", "_")
+
+// modemPrefix returns the paths' prefix for the modem named name: prefix,
+// followed by the name if perModem is set.
+func modemPrefix(prefix, name string, perModem bool) string {
+	if !perModem {
+		return prefix
+	}
+	return prefix + "." + componentEscaper.Replace(name)
+}
+
+// Flatten returns the values of s as metrics under prefix, ordered by
+// direction, channel and name.
+func Flatten(prefix string, s *modem.Signal) []Metric {
+	var ms []Metric
+	add := func(dir string, ch modem.Channel, name string, v float64) {
+		ms = append(ms, Metric{
+			Path:  strings.Join([]string{prefix, dir, componentEscaper.Replace(string(ch)), name}, "."),
+			Value: v,
+		})
+	}
+	for _, ch := range s.DownstreamChannels() {
+		d := s.Downstream[ch]
+		add("downstream", ch, "power_level", d.PowerLevel)
+		add("downstream", ch, "snr", d.SNR)
+		add("downstream", ch, "unerrored", d.Unerrored)
+		add("downstream", ch, "correctable", d.Correctable)
+		add("downstream", ch, "uncorrectable", d.Uncorrectable)
+	}
+	for _, ch := range s.UpstreamChannels() {
+		u := s.Upstream[ch]
+		add("upstream", ch, "power_level", u.PowerLevel)
+		add("upstream", ch, "symbol_rate", u.SymbolRate)
+	}
+	return ms
+}
+
+func format(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func deadline(ctx context.Context) time.Time {
+	if d, ok := ctx.Deadline(); ok {
+		return d
+	}
+	return time.Now().Add(10 * time.Second)
+}
+
+// Graphite writes to a Graphite server's plaintext listener, connecting
+// for each Write.
+type Graphite struct {
+	// Addr is the host:port of the plaintext listener, usually port 2003.
+	Addr string
+	// Prefix is the first component of every path, e.g. "surfer".
+	Prefix string
+	// PerModem adds each Result's Modem to the paths after Prefix, so
+	// several modems don't write the same paths.
+	PerModem bool
+}
+
+// Write sends the signal in r as "path value timestamp" lines.  The modem's
+// name is only part of the path with PerModem.  Failed polls aren't sent.
+func (g *Graphite) Write(ctx context.Context, r *sink.Result) error {
+	if r.Err != nil {
+		return nil
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", g.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(deadline(ctx))
+	w := bufio.NewWriter(conn)
+	for _, m := range Flatten(modemPrefix(g.Prefix, r.Modem, g.PerModem), r.Signal) {
+		fmt.Fprintf(w, "%s %s %d\n", m.Path, format(m.Value), r.Time.Unix())
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// maxPacket is the most bytes sent in one StatsD datagram, to avoid IP
+// fragmentation on an ethernet MTU.
+const maxPacket = 1432
+
+// StatsD sends gauges to a StatsD server over UDP.
+type StatsD struct {
+	// Addr is the host:port of the server, usually port 8125.
+	Addr string
+	// Prefix is the first component of every path, e.g. "surfer".
+	Prefix string
+	// PerModem adds each Result's Modem to the paths after Prefix, as for
+	// Graphite.
+	PerModem bool
+}
+
+// Write sends the signal in r as "path:value|g" gauges, several to a
+// datagram.  StatsD timestamps values itself, so r.Time is ignored.
+func (sd *StatsD) Write(ctx context.Context, r *sink.Result) error {
+	if r.Err != nil {
+		return nil
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", sd.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(deadline(ctx))
+	var pkt []byte
+	flush := func() error {
+		if len(pkt) == 0 {
+			return nil
+		}
+		_, err := conn.Write(pkt)
+		pkt = pkt[:0]
+		return err
+	}
+	for _, m := range Flatten(modemPrefix(sd.Prefix, r.Modem, sd.PerModem), r.Signal) {
+		line := fmt.Sprintf("%s:%s|g\n", m.Path, format(m.Value))
+		// A signed gauge adjusts the previous value, so a negative
+		// value must follow a reset to zero.
+		if m.Value < 0 {
+			line = fmt.Sprintf("%s:0|g\n", m.Path) + line
+		}
+		if len(pkt)+len(line) > maxPacket {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		pkt = append(pkt, line...)
+	}
+	return flush()
+}
@@ -0,0 +1,167 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphite
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/sink"
+)
+
+var (
+	_ sink.Sink = &Graphite{}
+	_ sink.Sink = &StatsD{}
+)
+
+var signal = &modem.Signal{
+	Downstream: map[modem.Channel]*modem.Downstream{
+		"10": {PowerLevel: -1.5, SNR: 38.9, Correctable: 22563},
+		"9":  {PowerLevel: 2, SNR: 40},
+	},
+	Upstream: map[modem.Channel]*modem.Upstream{
+		"1.2": {PowerLevel: 45.25, SymbolRate: 5120},
+	},
+}
+
+func TestGraphite(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	got := make(chan string, 1)
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		b, _ := ioutil.ReadAll(c)
+		got <- string(b)
+	}()
+
+	g := &Graphite{Addr: l.Addr().String(), Prefix: "surfer"}
+	if err := g.Write(context.Background(), &sink.Result{Modem: "SB8200", Time: time.Unix(1600000000, 0), Signal: signal}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	want := `surfer.downstream.9.power_level 2 1600000000
+surfer.downstream.9.snr 40 1600000000
+surfer.downstream.9.unerrored 0 1600000000
+surfer.downstream.9.correctable 0 1600000000
+surfer.downstream.9.uncorrectable 0 1600000000
+surfer.downstream.10.power_level -1.5 1600000000
+surfer.downstream.10.snr 38.9 1600000000
+surfer.downstream.10.unerrored 0 1600000000
+surfer.downstream.10.correctable 22563 1600000000
+surfer.downstream.10.uncorrectable 0 1600000000
+surfer.upstream.1_2.power_level 45.25 1600000000
+surfer.upstream.1_2.symbol_rate 5120 1600000000
+`
+	if g := <-got; g != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", g, want)
+	}
+}
+
+func TestModemPrefix(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		perModem bool
+		want     string
+	}{
+		{"SB8200", false, "surfer"},
+		{"upstairs", true, "surfer.upstairs"},
+		{"living room.tv", true, "surfer.living_room_tv"},
+	} {
+		if got := modemPrefix("surfer", tc.name, tc.perModem); got != tc.want {
+			t.Errorf("modemPrefix(%q, %t) got %q want %q", tc.name, tc.perModem, got, tc.want)
+		}
+	}
+}
+
+func TestStatsD(t *testing.T) {
+	c, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// Enough channels to need more than one datagram.
+	s := &modem.Signal{Downstream: map[modem.Channel]*modem.Downstream{}}
+	for i := 0; i < 32; i++ {
+		s.Downstream[modem.Channel(string(rune('a'+i%26))+strings.Repeat("x", i/26))] = &modem.Downstream{PowerLevel: -1, SNR: 40}
+	}
+	sd := &StatsD{Addr: c.LocalAddr().String(), Prefix: "surfer"}
+	if err := sd.Write(context.Background(), &sink.Result{Modem: "SB8200", Time: time.Now(), Signal: s}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var lines []string
+	packets := 0
+	buf := make([]byte, 65536)
+	c.SetReadDeadline(time.Now().Add(time.Second))
+	for len(lines) < 32*6 {
+		n, _, err := c.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("Read failed after %d lines: %v", len(lines), err)
+		}
+		if n > maxPacket {
+			t.Errorf("Datagram of %d bytes exceeds %d", n, maxPacket)
+		}
+		packets++
+		lines = append(lines, strings.Split(strings.TrimSuffix(string(buf[:n]), "\n"), "\n")...)
+	}
+	if packets < 2 {
+		t.Errorf("Expected several datagrams, got %d", packets)
+	}
+	if want := []string{
+		"surfer.downstream.a.power_level:0|g",
+		"surfer.downstream.a.power_level:-1|g",
+		"surfer.downstream.a.snr:40|g",
+		"surfer.downstream.a.unerrored:0|g",
+		"surfer.downstream.a.correctable:0|g",
+		"surfer.downstream.a.uncorrectable:0|g",
+	}; !reflect.DeepEqual(lines[:6], want) {
+		t.Errorf("Got %q want %q", lines[:6], want)
+	}
+}
+
+func TestStatsDPerModem(t *testing.T) {
+	c, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	sd := &StatsD{Addr: c.LocalAddr().String(), Prefix: "surfer", PerModem: true}
+	s := &modem.Signal{Upstream: map[modem.Channel]*modem.Upstream{"1": {PowerLevel: 45, SymbolRate: 5120}}}
+	if err := sd.Write(context.Background(), &sink.Result{Modem: "upstairs", Time: time.Now(), Signal: s}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	buf := make([]byte, 65536)
+	c.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := c.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if got, want := string(buf[:n]), "surfer.upstairs.upstream.1.power_level:45|g\nsurfer.upstairs.upstream.1.symbol_rate:5120|g\n"; got != want {
+		t.Errorf("Got %q want %q", got, want)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package influx encodes a modem.Signal as InfluxDB line protocol, and writes
+// it to an InfluxDB v2 server.
+//
+// Each downstream channel is a point in the "downstream" measurement, and
+// each upstream channel one in "upstream", tagged with the modem, channel,
+// frequency and modulation.
+package influx
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/sink"
+)
+
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	tagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+)
+
+type tag struct {
+	k, v string
+}
+
+type field struct {
+	k string
+	// v is the encoded value, e.g. "1.5" or "42i".
+	v string
+}
+
+func float(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func integer(v float64) string {
+	return strconv.FormatInt(int64(v), 10) + "i"
+}
+
+func writePoint(w io.Writer, measurement string, tags []tag, fields []field, t time.Time) error {
+	var b strings.Builder
+	b.WriteString(measurementEscaper.Replace(measurement))
+	// Influx prefers tags sorted by key.
+	sort.Slice(tags, func(i, j int) bool { return tags[i].k < tags[j].k })
+	for _, t := range tags {
+		// Empty tag values aren't allowed.
+		if t.v == "" {
+			continue
+		}
+		fmt.Fprintf(&b, ",%s=%s", tagEscaper.Replace(t.k), tagEscaper.Replace(t.v))
+	}
+	for i, f := range fields {
+		sep := ","
+		if i == 0 {
+			sep = " "
+		}
+		fmt.Fprintf(&b, "%s%s=%s", sep, tagEscaper.Replace(f.k), f.v)
+	}
+	fmt.Fprintf(&b, " %d\n", t.UnixNano())
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Encode writes s, read from the modem named name at t, to w as line
+// protocol with nanosecond timestamps, one line per channel.
+func Encode(w io.Writer, name string, t time.Time, s *modem.Signal) error {
+	for _, ch := range s.DownstreamChannels() {
+		d := s.Downstream[ch]
+		tags := []tag{
+			{"modem", name},
+			{"channel", string(ch)},
+			{"frequency", d.Frequency},
+			{"modulation", d.Modulation},
+		}
+		fields := []field{
+			{"power_level", float(d.PowerLevel)},
+			{"snr", float(d.SNR)},
+			{"unerrored", integer(d.Unerrored)},
+			{"correctable", integer(d.Correctable)},
+			{"uncorrectable", integer(d.Uncorrectable)},
+		}
+		if err := writePoint(w, "downstream", tags, fields, t); err != nil {
+			return err
+		}
+	}
+	for _, ch := range s.UpstreamChannels() {
+		u := s.Upstream[ch]
+		tags := []tag{
+			{"modem", name},
+			{"channel", string(ch)},
+			{"frequency", u.Frequency},
+			{"modulation", u.Modulation},
+			{"status", u.Status},
+		}
+		fields := []field{
+			{"power_level", float(u.PowerLevel)},
+			{"symbol_rate", float(u.SymbolRate)},
+		}
+		if err := writePoint(w, "upstream", tags, fields, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Client writes to the /api/v2/write endpoint of an InfluxDB v2 server.
+type Client struct {
+	// URL is the server's base URL, e.g. "http://localhost:8086".
+	URL    string
+	Org    string
+	Bucket string
+	// Token, if not empty, authorizes writes.
+	Token string
+	// HTTPClient makes the requests, http.DefaultClient if nil.
+	HTTPClient *http.Client
+}
+
+// Write encodes the signal in r and writes it to the server.  Failed polls
+// aren't written.
+func (c *Client) Write(ctx context.Context, r *sink.Result) error {
+	if r.Err != nil {
+		return nil
+	}
+	var body bytes.Buffer
+	if err := Encode(&body, r.Modem, r.Time, r.Signal); err != nil {
+		return err
+	}
+	q := url.Values{
+		"org":       {c.Org},
+		"bucket":    {c.Bucket},
+		"precision": {"ns"},
+	}
+	req, err := http.NewRequest("POST", strings.TrimSuffix(c.URL, "/")+"/api/v2/write?"+q.Encode(), &body)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Token "+c.Token)
+	}
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("POST %s: %s: %s", req.URL.Path, resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
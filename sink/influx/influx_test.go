@@ -0,0 +1,95 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package influx
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/sink"
+)
+
+var _ sink.Sink = &Client{}
+
+var (
+	t0     = time.Unix(1600000000, 5)
+	signal = &modem.Signal{
+		Downstream: map[modem.Channel]*modem.Downstream{
+			"10": {Frequency: "609000000", Modulation: "QAM256", PowerLevel: -1.5, SNR: 38.9, Correctable: 22563, Unerrored: 110946},
+			"9":  {Frequency: "603000000", Modulation: "OFDM PLC", PowerLevel: 2, SNR: 40},
+		},
+		Upstream: map[modem.Channel]*modem.Upstream{
+			"1": {Frequency: "38600000", Modulation: "64QAM", PowerLevel: 45.25, SymbolRate: 5.12e6},
+		},
+	}
+	result = &sink.Result{Modem: "Netgear CM1000", Time: t0, Signal: signal}
+	want   = `downstream,channel=9,frequency=603000000,modem=Netgear\ CM1000,modulation=OFDM\ PLC power_level=2,snr=40,unerrored=0i,correctable=0i,uncorrectable=0i 1600000000000000005
+downstream,channel=10,frequency=609000000,modem=Netgear\ CM1000,modulation=QAM256 power_level=-1.5,snr=38.9,unerrored=110946i,correctable=22563i,uncorrectable=0i 1600000000000000005
+upstream,channel=1,frequency=38600000,modem=Netgear\ CM1000,modulation=64QAM power_level=45.25,symbol_rate=5120000 1600000000000000005
+`
+)
+
+func TestEncode(t *testing.T) {
+	var b strings.Builder
+	if err := Encode(&b, "Netgear CM1000", t0, signal); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if got := b.String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+}
+
+func TestClient(t *testing.T) {
+	var got, query, auth string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/write" {
+			http.NotFound(w, r)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		got, query, auth = string(b), r.URL.RawQuery, r.Header.Get("Authorization")
+		if r.URL.Query().Get("bucket") == "missing" {
+			http.Error(w, `{"code":"not found","message":"bucket not found"}`, http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer s.Close()
+
+	c := &Client{URL: s.URL + "/", Org: "home", Bucket: "modem", Token: "secret", HTTPClient: s.Client()}
+	if err := c.Write(context.Background(), result); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("Server got:\n%s\nWant:\n%s", got, want)
+	}
+	if want := "bucket=modem&org=home&precision=ns"; query != want {
+		t.Errorf("Query got %q want %q", query, want)
+	}
+	if want := "Token secret"; auth != want {
+		t.Errorf("Authorization got %q want %q", auth, want)
+	}
+
+	c.Bucket = "missing"
+	if err := c.Write(context.Background(), result); err == nil || !strings.Contains(err.Error(), "bucket not found") {
+		t.Errorf("Expected error with server's message, got %v", err)
+	}
+}
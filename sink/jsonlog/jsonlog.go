@@ -0,0 +1,78 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jsonlog logs the result of each poll as a line of JSON.
+package jsonlog
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/modem/health"
+	"github.com/wathiede/surfer/sink"
+)
+
+// Record is the JSON form of a sink.Result.
+type Record struct {
+	Modem string    `json:"modem"`
+	Time  time.Time `json:"time"`
+	// Duration is how long the poll took, in seconds.
+	Duration float64 `json:"duration_seconds"`
+	*modem.Signal
+	Health *health.Report `json:"health,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// NewRecord returns the Record for r.
+func NewRecord(r *sink.Result) Record {
+	rec := Record{
+		Modem:    r.Modem,
+		Time:     r.Time,
+		Duration: r.Duration.Seconds(),
+		Signal:   r.Signal,
+		Health:   r.Health,
+	}
+	if r.Err != nil {
+		rec.Error = r.Err.Error()
+	}
+	return rec
+}
+
+// Sink writes a Record for each Result to a writer.
+type Sink struct {
+	w io.Writer
+	e *json.Encoder
+}
+
+// New returns a Sink writing to w.  If w is an io.Closer, closing the Sink
+// closes it.
+func New(w io.Writer) *Sink {
+	return &Sink{w: w, e: json.NewEncoder(w)}
+}
+
+// Write logs r.
+func (s *Sink) Write(_ context.Context, r *sink.Result) error {
+	return s.e.Encode(NewRecord(r))
+}
+
+// Close closes the underlying writer, if it's an io.Closer.
+func (s *Sink) Close() error {
+	if c, ok := s.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
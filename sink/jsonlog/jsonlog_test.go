@@ -0,0 +1,52 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonlog
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/sink"
+)
+
+func TestWrite(t *testing.T) {
+	var b strings.Builder
+	s := New(&b)
+	t0 := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, r := range []*sink.Result{
+		{Modem: "SB8200", Time: t0, Duration: 250 * time.Millisecond, Signal: &modem.Signal{
+			Downstream: map[modem.Channel]*modem.Downstream{"1": {Frequency: "591000000", Modulation: "QAM256", PowerLevel: 1.5, SNR: 40}},
+			Upstream:   map[modem.Channel]*modem.Upstream{},
+		}},
+		{Modem: "SB8200", Time: t0.Add(time.Minute), Duration: time.Second, Err: errors.New("context deadline exceeded")},
+	} {
+		if err := s.Write(context.Background(), r); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	want := `{"modem":"SB8200","time":"2026-01-02T03:04:05Z","duration_seconds":0.25,"downstream":{"1":{"correctable":0,"frequency":"591000000","modulation":"QAM256","power_level":1.5,"snr":40,"uncorrectable":0,"unerrored":0}},"upstream":{}}
+{"modem":"SB8200","time":"2026-01-02T03:05:05Z","duration_seconds":1,"error":"context deadline exceeded"}
+`
+	if got := b.String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+}
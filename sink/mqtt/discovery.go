@@ -0,0 +1,134 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mqtt
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/wathiede/surfer/modem"
+)
+
+// device groups the sensors of one modem in Home Assistant.
+type device struct {
+	Identifiers []string `json:"identifiers"`
+	Name        string   `json:"name"`
+	Model       string   `json:"model,omitempty"`
+}
+
+// sensorConfig is the discovery config of a Home Assistant MQTT sensor, see
+// https://www.home-assistant.io/integrations/sensor.mqtt/.
+type sensorConfig struct {
+	Name              string   `json:"name"`
+	UniqueID          string   `json:"unique_id"`
+	ObjectID          string   `json:"object_id"`
+	StateTopic        string   `json:"state_topic"`
+	ValueTemplate     string   `json:"value_template,omitempty"`
+	Unit              string   `json:"unit_of_measurement,omitempty"`
+	DeviceClass       string   `json:"device_class,omitempty"`
+	StateClass        string   `json:"state_class,omitempty"`
+	Options           []string `json:"options,omitempty"`
+	AvailabilityTopic string   `json:"availability_topic"`
+	Device            device   `json:"device"`
+}
+
+// sensor describes one value of a channel's state.
+type sensor struct {
+	key, name, unit, deviceClass, stateClass string
+}
+
+var (
+	grades = []string{"good", "marginal", "bad"}
+
+	healthSensor = sensor{key: "health", name: "health", deviceClass: "enum"}
+
+	downstreamSensors = []sensor{
+		{key: "power_level", name: "power", unit: "dBmV", stateClass: "measurement"},
+		{key: "snr", name: "SNR", unit: "dB", deviceClass: "signal_strength", stateClass: "measurement"},
+		{key: "correctable", name: "correctable codewords", stateClass: "total_increasing"},
+		{key: "uncorrectable", name: "uncorrectable codewords", stateClass: "total_increasing"},
+		healthSensor,
+	}
+	upstreamSensors = []sensor{
+		{key: "power_level", name: "power", unit: "dBmV", stateClass: "measurement"},
+		healthSensor,
+	}
+)
+
+// objectID returns s with anything but letters, digits and underscores
+// replaced by underscores, as allowed in discovery topics.
+func objectID(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// discovery returns the discovery configs for every sensor of s, read from
+// the modem named name, keyed by config topic.
+func (p *Publisher) discovery(name string, s *modem.Signal) map[string][]byte {
+	node := objectID(p.o.TopicPrefix)
+	dev := device{Identifiers: []string{node}, Name: name, Model: name}
+	if p.o.PerModem {
+		// name is the modem's name rather than its model.
+		node = objectID(p.o.TopicPrefix + "_" + name)
+		dev = device{Identifiers: []string{node}, Name: name}
+	}
+	base := p.stateTopic(name)
+	configs := map[string][]byte{}
+	add := func(id string, c sensorConfig) {
+		c.UniqueID = node + "_" + id
+		c.ObjectID = c.UniqueID
+		c.AvailabilityTopic = p.availabilityTopic()
+		c.Device = dev
+		b, err := json.Marshal(c)
+		if err != nil {
+			panic(err)
+		}
+		configs[fmt.Sprintf("%s/sensor/%s/%s/config", p.o.DiscoveryPrefix, node, id)] = b
+	}
+	channel := func(dir string, ch modem.Channel, sensors []sensor) {
+		for _, sn := range sensors {
+			c := sensorConfig{
+				Name:          fmt.Sprintf("%s %s %s", strings.ToUpper(dir[:1])+dir[1:], ch, sn.name),
+				StateTopic:    base + "/" + dir + "/" + string(ch),
+				ValueTemplate: "{{ value_json." + sn.key + " }}",
+				Unit:          sn.unit,
+				DeviceClass:   sn.deviceClass,
+				StateClass:    sn.stateClass,
+			}
+			if sn.deviceClass == "enum" {
+				c.Options = grades
+			}
+			add(objectID(fmt.Sprintf("%s_%s_%s", dir, ch, sn.key)), c)
+		}
+	}
+	for _, ch := range s.DownstreamChannels() {
+		channel("downstream", ch, downstreamSensors)
+	}
+	for _, ch := range s.UpstreamChannels() {
+		channel("upstream", ch, upstreamSensors)
+	}
+	add("health", sensorConfig{
+		Name:        "Health",
+		StateTopic:  base + "/health",
+		DeviceClass: "enum",
+		Options:     grades,
+	})
+	return configs
+}
@@ -0,0 +1,312 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mqtt publishes a modem.Signal to an MQTT broker, announcing each
+// value as a Home Assistant sensor through MQTT discovery.
+//
+// With the default TopicPrefix, the state of downstream channel 3 is
+// published as JSON to surfer/downstream/3, upstream channels likewise under
+// surfer/upstream, and the modem's overall health to surfer/health.
+// surfer/status is "online" while connected, and "offline" otherwise.
+// Discovery configs are retained under homeassistant/sensor/, and removed
+// when a channel goes away.  With PerModem, the topics of each modem are
+// under its name, e.g. surfer/upstairs/downstream/3, and it's a device of
+// its own in Home Assistant.
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/modem/health"
+	"github.com/wathiede/surfer/sink"
+)
+
+// Options configure a Publisher.
+type Options struct {
+	// Broker is the host:port of the MQTT broker.  A tcp:// or mqtt://
+	// prefix is ignored, and the port defaults to 1883.
+	Broker string
+	// ClientID identifies the connection to the broker, TopicPrefix if
+	// empty.
+	ClientID string
+	Username string
+	Password string
+	// TopicPrefix prefixes the state topics, "surfer" if empty.
+	TopicPrefix string
+	// DiscoveryPrefix is Home Assistant's discovery prefix,
+	// "homeassistant" if empty.
+	DiscoveryPrefix string
+	// KeepAlive is how often the connection is checked while idle, 60
+	// seconds if zero.
+	KeepAlive time.Duration
+	// PerModem publishes each Result under its Modem, so several modems
+	// can share a Publisher.
+	PerModem bool
+}
+
+// Publisher publishes signal to an MQTT broker, connecting on first use and
+// reconnecting whenever the connection is lost.
+type Publisher struct {
+	o Options
+
+	mu   sync.Mutex
+	conn net.Conn
+	// configs holds the discovery configs last announced for each
+	// modem, by topic.
+	configs map[string]map[string][]byte
+	// announced holds the modems whose configs were announced on the
+	// current connection.  It's reset on connecting, so all configs are
+	// announced again in case the broker lost them.
+	announced map[string]bool
+}
+
+// New returns a Publisher for o.  It doesn't connect until Write is
+// called.
+func New(o Options) *Publisher {
+	o.Broker = strings.TrimPrefix(strings.TrimPrefix(o.Broker, "tcp://"), "mqtt://")
+	if _, _, err := net.SplitHostPort(o.Broker); err != nil {
+		o.Broker = net.JoinHostPort(o.Broker, "1883")
+	}
+	if o.TopicPrefix == "" {
+		o.TopicPrefix = "surfer"
+	}
+	if o.ClientID == "" {
+		o.ClientID = o.TopicPrefix
+	}
+	if o.DiscoveryPrefix == "" {
+		o.DiscoveryPrefix = "homeassistant"
+	}
+	if o.KeepAlive == 0 {
+		o.KeepAlive = time.Minute
+	}
+	return &Publisher{o: o, configs: map[string]map[string][]byte{}, announced: map[string]bool{}}
+}
+
+func (p *Publisher) availabilityTopic() string {
+	return p.o.TopicPrefix + "/status"
+}
+
+// Write publishes the signal and health in r.  Failed polls aren't
+// published.  If the connection has been lost it reconnects, and if
+// publishing on an existing connection fails it retries once on a new one.
+func (p *Publisher) Write(ctx context.Context, r *sink.Result) error {
+	if r.Err != nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	reused := p.conn != nil
+	err := p.publish(ctx, r.Modem, r.Signal, r.Health)
+	if err != nil && reused {
+		glog.Warningf("Reconnecting to MQTT broker %s: %v", p.o.Broker, err)
+		err = p.publish(ctx, r.Modem, r.Signal, r.Health)
+	}
+	return err
+}
+
+// publish publishes s, connecting first if needed.  p.mu must be held.
+func (p *Publisher) publish(ctx context.Context, name string, s *modem.Signal, h *health.Report) error {
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(10 * time.Second)
+	}
+	p.conn.SetWriteDeadline(deadline)
+	err := p.write(name, s, h)
+	if err != nil {
+		p.disconnect()
+	}
+	return err
+}
+
+// stateTopic returns the prefix of the state topics of the modem named
+// name.
+func (p *Publisher) stateTopic(name string) string {
+	if !p.o.PerModem {
+		return p.o.TopicPrefix
+	}
+	return p.o.TopicPrefix + "/" + objectID(name)
+}
+
+func (p *Publisher) write(name string, s *modem.Signal, h *health.Report) error {
+	configs := p.discovery(name, s)
+	prev := p.configs[name]
+	for topic, c := range configs {
+		if old, ok := prev[topic]; ok && p.announced[name] && string(old) == string(c) {
+			continue
+		}
+		if err := writePacket(p.conn, publishPacket(topic, c, true)); err != nil {
+			return err
+		}
+	}
+	for topic := range prev {
+		if _, ok := configs[topic]; ok {
+			continue
+		}
+		// An empty retained config removes the sensor.
+		if err := writePacket(p.conn, publishPacket(topic, nil, true)); err != nil {
+			return err
+		}
+	}
+	p.configs[name] = configs
+	p.announced[name] = true
+	base := p.stateTopic(name)
+
+	for _, ch := range s.DownstreamChannels() {
+		b, err := json.Marshal(downstreamState{Downstream: s.Downstream[ch], Health: h.Downstream[ch].Grade})
+		if err != nil {
+			return err
+		}
+		if err := writePacket(p.conn, publishPacket(base+"/downstream/"+string(ch), b, false)); err != nil {
+			return err
+		}
+	}
+	for _, ch := range s.UpstreamChannels() {
+		b, err := json.Marshal(upstreamState{Upstream: s.Upstream[ch], Health: h.Upstream[ch].Grade})
+		if err != nil {
+			return err
+		}
+		if err := writePacket(p.conn, publishPacket(base+"/upstream/"+string(ch), b, false)); err != nil {
+			return err
+		}
+	}
+	return writePacket(p.conn, publishPacket(base+"/health", []byte(h.Overall.String()), true))
+}
+
+type downstreamState struct {
+	*modem.Downstream
+	Health health.Grade `json:"health"`
+}
+
+type upstreamState struct {
+	*modem.Upstream
+	Health health.Grade `json:"health"`
+}
+
+// connect dials the broker and waits for it to accept the connection.
+// p.mu must be held.
+func (p *Publisher) connect(ctx context.Context) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", p.o.Broker)
+	if err != nil {
+		return err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(10 * time.Second)
+	}
+	conn.SetDeadline(deadline)
+	keepAlive := int(p.o.KeepAlive / time.Second)
+	if err := writePacket(conn, connectPacket(p.o.ClientID, p.o.Username, p.o.Password, keepAlive, p.availabilityTopic(), "offline")); err != nil {
+		conn.Close()
+		return err
+	}
+	r := bufio.NewReader(conn)
+	ack, err := readPacket(r)
+	if err == nil {
+		err = connackError(ack)
+	}
+	if err == nil {
+		err = writePacket(conn, publishPacket(p.availabilityTopic(), []byte("online"), true))
+	}
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("MQTT broker %s: %v", p.o.Broker, err)
+	}
+	conn.SetDeadline(time.Time{})
+	glog.Infof("Connected to MQTT broker %s", p.o.Broker)
+	p.conn = conn
+	p.announced = map[string]bool{}
+	done := make(chan struct{})
+	go p.read(conn, r, done)
+	go p.ping(conn, done)
+	return nil
+}
+
+// read discards packets from the broker until conn fails, then drops it so
+// the next Write reconnects.
+func (p *Publisher) read(conn net.Conn, r *bufio.Reader, done chan struct{}) {
+	defer close(done)
+	for {
+		if _, err := readPacket(r); err != nil {
+			p.mu.Lock()
+			if p.conn == conn {
+				glog.Errorf("Lost connection to MQTT broker %s: %v", p.o.Broker, err)
+				p.disconnect()
+			}
+			p.mu.Unlock()
+			return
+		}
+	}
+}
+
+// ping sends a PINGREQ every half KeepAlive until done is closed, so the
+// broker doesn't drop the connection between polls.
+func (p *Publisher) ping(conn net.Conn, done chan struct{}) {
+	t := time.NewTicker(p.o.KeepAlive / 2)
+	defer t.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-t.C:
+			p.mu.Lock()
+			if p.conn == conn {
+				conn.SetWriteDeadline(time.Now().Add(p.o.KeepAlive / 2))
+				if err := writePacket(conn, packet{typ: pingreqType}); err != nil {
+					p.disconnect()
+				}
+			}
+			p.mu.Unlock()
+		}
+	}
+}
+
+// disconnect closes the connection.  p.mu must be held.
+func (p *Publisher) disconnect() {
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn = nil
+	}
+}
+
+// Close marks the modem offline and disconnects from the broker.
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		return nil
+	}
+	p.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	err := writePacket(p.conn, publishPacket(p.availabilityTopic(), []byte("offline"), true))
+	if err == nil {
+		err = writePacket(p.conn, packet{typ: disconnectType})
+	}
+	p.disconnect()
+	return err
+}
@@ -0,0 +1,335 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/modem/health"
+	"github.com/wathiede/surfer/sink"
+)
+
+var _ sink.Sink = &Publisher{}
+
+// message is a PUBLISH received by broker.
+type message struct {
+	topic   string
+	payload string
+	retain  bool
+}
+
+// broker is an in-process MQTT broker that accepts every connection and
+// records what's published.
+type broker struct {
+	l        net.Listener
+	messages chan message
+	conns    chan net.Conn
+	// connects receives the client ID and username of each CONNECT.
+	connects chan [2]string
+}
+
+func newBroker(t *testing.T) *broker {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &broker{
+		l:        l,
+		messages: make(chan message, 1000),
+		conns:    make(chan net.Conn, 10),
+		connects: make(chan [2]string, 10),
+	}
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			b.conns <- c
+			go b.serve(t, c)
+		}
+	}()
+	return b
+}
+
+func readString(b []byte) (string, []byte) {
+	n := int(b[0])<<8 | int(b[1])
+	return string(b[2 : 2+n]), b[2+n:]
+}
+
+func (b *broker) serve(t *testing.T, c net.Conn) {
+	defer c.Close()
+	r := bufio.NewReader(c)
+	for {
+		p, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		switch p.typ {
+		case connectType:
+			proto, rest := readString(p.body)
+			if proto != "MQTT" || rest[0] != 4 {
+				t.Errorf("CONNECT with protocol %q level %d", proto, rest[0])
+			}
+			flags := rest[1]
+			id, rest := readString(rest[4:])
+			_, rest = readString(rest) // will topic
+			_, rest = readString(rest) // will message
+			var user string
+			if flags&usernameFlag != 0 {
+				user, _ = readString(rest)
+			}
+			b.connects <- [2]string{id, user}
+			writePacket(c, packet{typ: connackType, body: []byte{0, 0}})
+		case publishType:
+			topic, payload := readString(p.body)
+			b.messages <- message{topic: topic, payload: string(payload), retain: p.flags&1 != 0}
+		case pingreqType:
+			writePacket(c, packet{typ: pingrespType})
+		case disconnectType:
+			return
+		}
+	}
+}
+
+// drain returns the messages received within a short time, keyed by topic.
+func (b *broker) drain(t *testing.T) map[string]message {
+	t.Helper()
+	got := map[string]message{}
+	for {
+		select {
+		case m := <-b.messages:
+			got[m.topic] = m
+		case <-time.After(200 * time.Millisecond):
+			return got
+		}
+	}
+}
+
+var signal = &modem.Signal{
+	Downstream: map[modem.Channel]*modem.Downstream{
+		"1": {Frequency: "591000000", Modulation: "QAM256", PowerLevel: 1.5, SNR: 40.1, Correctable: 12},
+		"2": {Frequency: "597000000", Modulation: "QAM256", PowerLevel: -12, SNR: 38.2},
+	},
+	Upstream: map[modem.Channel]*modem.Upstream{
+		"3": {Frequency: "35600000", Modulation: "ATDMA", PowerLevel: 44, SymbolRate: 5120},
+	},
+}
+
+func result(s *modem.Signal) *sink.Result {
+	return &sink.Result{Modem: "SB8200", Time: time.Now(), Signal: s, Health: health.DOCSIS().Evaluate(s)}
+}
+
+func TestPublish(t *testing.T) {
+	b := newBroker(t)
+	defer b.l.Close()
+	p := New(Options{Broker: "tcp://" + b.l.Addr().String(), Username: "ha"})
+	ctx := context.Background()
+	if err := p.Write(ctx, result(signal)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if got, want := <-b.connects, [2]string{"surfer", "ha"}; got != want {
+		t.Errorf("CONNECT client ID and username got %q want %q", got, want)
+	}
+	got := b.drain(t)
+	// 5 sensors for each downstream channel, 2 for upstream and the
+	// overall health, plus 3 channel states, health and availability.
+	if len(got) != 5*2+2+1+5 {
+		t.Errorf("Got %d topics, want %d: %v", len(got), 5*2+2+1+5, got)
+	}
+	if m := got["surfer/status"]; m.payload != "online" || !m.retain {
+		t.Errorf("Availability got %+v", m)
+	}
+	if m := got["surfer/health"]; m.payload != "bad" {
+		t.Errorf("Health got %+v", m)
+	}
+
+	var state map[string]interface{}
+	if err := json.Unmarshal([]byte(got["surfer/downstream/1"].payload), &state); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]interface{}{
+		"frequency": "591000000", "modulation": "QAM256", "power_level": 1.5, "snr": 40.1,
+		"correctable": 12.0, "uncorrectable": 0.0, "unerrored": 0.0, "health": "good",
+	}; !reflect.DeepEqual(state, want) {
+		t.Errorf("Downstream state got %v want %v", state, want)
+	}
+
+	m, ok := got["homeassistant/sensor/surfer/downstream_2_snr/config"]
+	if !ok || !m.retain {
+		t.Fatalf("Missing retained SNR config, got %+v", m)
+	}
+	var c sensorConfig
+	if err := json.Unmarshal([]byte(m.payload), &c); err != nil {
+		t.Fatal(err)
+	}
+	if want := (sensorConfig{
+		Name:              "Downstream 2 SNR",
+		UniqueID:          "surfer_downstream_2_snr",
+		ObjectID:          "surfer_downstream_2_snr",
+		StateTopic:        "surfer/downstream/2",
+		ValueTemplate:     "{{ value_json.snr }}",
+		Unit:              "dB",
+		DeviceClass:       "signal_strength",
+		StateClass:        "measurement",
+		AvailabilityTopic: "surfer/status",
+		Device:            device{Identifiers: []string{"surfer"}, Name: "SB8200", Model: "SB8200"},
+	}); !reflect.DeepEqual(c, want) {
+		t.Errorf("Config got %+v want %+v", c, want)
+	}
+
+	// Unchanged configs aren't announced again, and those of a removed
+	// channel are cleared.
+	s := &modem.Signal{Downstream: map[modem.Channel]*modem.Downstream{"1": signal.Downstream["1"]}, Upstream: signal.Upstream}
+	if err := p.Write(ctx, result(s)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	got = b.drain(t)
+	var configs []string
+	for topic, m := range got {
+		if strings.HasSuffix(topic, "/config") {
+			if m.payload != "" {
+				t.Errorf("Expected %s to be cleared, got %q", topic, m.payload)
+			}
+			configs = append(configs, topic)
+		}
+	}
+	if len(configs) != 5 {
+		t.Errorf("Expected channel 2's 5 configs cleared, got %q", configs)
+	}
+
+	// After the broker drops the connection, the next Write reconnects
+	// and announces everything again.
+	(<-b.conns).Close()
+	time.Sleep(100 * time.Millisecond)
+	if err := p.Write(ctx, result(s)); err != nil {
+		t.Fatalf("Write after disconnect failed: %v", err)
+	}
+	<-b.connects
+	got = b.drain(t)
+	if _, ok := got["homeassistant/sensor/surfer/downstream_1_snr/config"]; !ok {
+		t.Errorf("Expected configs announced again after reconnecting, got %v", got)
+	}
+
+	if err := p.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if m := b.drain(t)["surfer/status"]; m.payload != "offline" {
+		t.Errorf("Availability after Close got %+v", m)
+	}
+}
+
+func TestPublishPerModem(t *testing.T) {
+	b := newBroker(t)
+	defer b.l.Close()
+	p := New(Options{Broker: b.l.Addr().String(), PerModem: true})
+	defer p.Close()
+	ctx := context.Background()
+	for _, name := range []string{"upstairs", "office"} {
+		r := result(signal)
+		r.Modem = name
+		if err := p.Write(ctx, r); err != nil {
+			t.Fatalf("Write of %s failed: %v", name, err)
+		}
+	}
+	got := b.drain(t)
+	for _, name := range []string{"upstairs", "office"} {
+		if m, ok := got["surfer/"+name+"/downstream/1"]; !ok || m.payload == "" {
+			t.Errorf("Missing downstream state of %s, got %+v", name, m)
+		}
+		if m := got["surfer/"+name+"/health"]; m.payload != "bad" {
+			t.Errorf("Health of %s got %+v", name, m)
+		}
+		m, ok := got["homeassistant/sensor/surfer_"+name+"/downstream_2_snr/config"]
+		if !ok || m.payload == "" {
+			t.Fatalf("Missing SNR config of %s, got %+v", name, m)
+		}
+		var c sensorConfig
+		if err := json.Unmarshal([]byte(m.payload), &c); err != nil {
+			t.Fatal(err)
+		}
+		if c.UniqueID != "surfer_"+name+"_downstream_2_snr" || c.StateTopic != "surfer/"+name+"/downstream/2" ||
+			!reflect.DeepEqual(c.Device, device{Identifiers: []string{"surfer_" + name}, Name: name}) {
+			t.Errorf("Config of %s got %+v", name, c)
+		}
+	}
+	if _, ok := got["surfer/downstream/1"]; ok {
+		t.Errorf("Got state published without the modem's name")
+	}
+
+	// Polling one modem again leaves the other's configs alone.
+	r := result(signal)
+	r.Modem = "office"
+	if err := p.Write(ctx, r); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	for topic, m := range b.drain(t) {
+		if strings.HasSuffix(topic, "/config") {
+			t.Errorf("Got %s announced again: %q", topic, m.payload)
+		}
+	}
+}
+
+func TestPublishRefused(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		readPacket(bufio.NewReader(c))
+		writePacket(c, packet{typ: connackType, body: []byte{0, 4}})
+	}()
+	p := New(Options{Broker: l.Addr().String()})
+	err = p.Write(context.Background(), result(signal))
+	if err == nil || !strings.Contains(err.Error(), "bad user name or password") {
+		t.Errorf("Expected bad credentials error, got %v", err)
+	}
+}
+
+func TestConnectPacketPassword(t *testing.T) {
+	for _, tc := range []struct {
+		username, password string
+		want               byte
+	}{
+		{"", "", 0},
+		{"ha", "", usernameFlag},
+		{"ha", "secret", usernameFlag | passwordFlag},
+		// A password is never sent without a user name.
+		{"", "secret", 0},
+	} {
+		p := connectPacket("surfer", tc.username, tc.password, 60, "surfer/status", "offline")
+		_, rest := readString(p.body)
+		if got := rest[1] & (usernameFlag | passwordFlag); got != tc.want {
+			t.Errorf("connectPacket(%q, %q) got flags %#x want %#x", tc.username, tc.password, got, tc.want)
+		}
+		if tc.want&passwordFlag == 0 && strings.Contains(string(p.body), "secret") {
+			t.Errorf("connectPacket(%q, %q) sent the password", tc.username, tc.password)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mqtt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The MQTT 3.1.1 control packet types used here.
+const (
+	connectType    = 1
+	connackType    = 2
+	publishType    = 3
+	pingreqType    = 12
+	pingrespType   = 13
+	disconnectType = 14
+)
+
+// packet is an MQTT control packet.
+type packet struct {
+	// typ is the high nibble of the fixed header.
+	typ byte
+	// flags is the low nibble of the fixed header.
+	flags byte
+	// body is the variable header and payload.
+	body []byte
+}
+
+func writePacket(w io.Writer, p packet) error {
+	b := []byte{p.typ<<4 | p.flags}
+	// The remaining length is 7 bits per byte, least significant first,
+	// with the top bit set on all but the last byte.
+	n := len(p.body)
+	for {
+		d := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			d |= 0x80
+		}
+		b = append(b, d)
+		if n == 0 {
+			break
+		}
+	}
+	_, err := w.Write(append(b, p.body...))
+	return err
+}
+
+func readPacket(r *bufio.Reader) (packet, error) {
+	h, err := r.ReadByte()
+	if err != nil {
+		return packet{}, err
+	}
+	n, mul := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return packet{}, errors.New("malformed remaining length")
+		}
+		d, err := r.ReadByte()
+		if err != nil {
+			return packet{}, err
+		}
+		n += int(d&0x7f) * mul
+		mul *= 128
+		if d&0x80 == 0 {
+			break
+		}
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return packet{}, err
+	}
+	return packet{typ: h >> 4, flags: h & 0x0f, body: body}, nil
+}
+
+func appendString(b []byte, s string) []byte {
+	b = append(b, byte(len(s)>>8), byte(len(s)))
+	return append(b, s...)
+}
+
+// CONNECT flags.
+const (
+	cleanSessionFlag = 0x02
+	willFlag         = 0x04
+	willRetainFlag   = 0x20
+	passwordFlag     = 0x40
+	usernameFlag     = 0x80
+)
+
+// connectPacket returns a CONNECT with a clean session and a retained will
+// message, publishing willPayload to willTopic at QoS 0 if the connection is
+// lost.
+func connectPacket(clientID, username, password string, keepAlive int, willTopic, willPayload string) packet {
+	flags := byte(cleanSessionFlag | willFlag | willRetainFlag)
+	if username != "" {
+		flags |= usernameFlag
+	}
+	// MQTT 3.1.1 section 3.1.2.9 forbids a password without a user name.
+	if username == "" {
+		password = ""
+	}
+	if password != "" {
+		flags |= passwordFlag
+	}
+	b := appendString(nil, "MQTT")
+	b = append(b, 4, flags, byte(keepAlive>>8), byte(keepAlive))
+	b = appendString(b, clientID)
+	b = appendString(b, willTopic)
+	b = appendString(b, willPayload)
+	if username != "" {
+		b = appendString(b, username)
+	}
+	if password != "" {
+		b = appendString(b, password)
+	}
+	return packet{typ: connectType, body: b}
+}
+
+// connackError returns an error for a CONNACK refusing the connection.
+func connackError(p packet) error {
+	if p.typ != connackType || len(p.body) != 2 {
+		return fmt.Errorf("expected CONNACK, got packet type %d", p.typ)
+	}
+	switch code := p.body[1]; code {
+	case 0:
+		return nil
+	case 1:
+		return errors.New("connection refused: unacceptable protocol version")
+	case 2:
+		return errors.New("connection refused: identifier rejected")
+	case 3:
+		return errors.New("connection refused: server unavailable")
+	case 4:
+		return errors.New("connection refused: bad user name or password")
+	case 5:
+		return errors.New("connection refused: not authorized")
+	default:
+		return fmt.Errorf("connection refused: code %d", code)
+	}
+}
+
+// publishPacket returns a QoS 0 PUBLISH.
+func publishPacket(topic string, payload []byte, retain bool) packet {
+	var flags byte
+	if retain {
+		flags = 0x01
+	}
+	return packet{typ: publishType, flags: flags, body: append(appendString(nil, topic), payload...)}
+}
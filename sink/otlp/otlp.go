@@ -0,0 +1,370 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otlp pushes the metrics in a prometheus registry to an
+// OpenTelemetry collector, using the JSON encoding of OTLP/HTTP.
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/wathiede/surfer/sink"
+)
+
+// firmwareAttribute is the resource attribute holding the modem's firmware
+// version.
+const firmwareAttribute = "modem.firmware"
+
+// Exporter pushes the metrics gathered from a prometheus registry to an
+// OTLP/HTTP endpoint.
+type Exporter struct {
+	// URL receives the metrics, e.g. "http://localhost:4318/v1/metrics".
+	URL string
+	// Headers are added to every request, e.g. for authorization.
+	Headers map[string]string
+	// Resource holds the attributes describing what the metrics are
+	// about, e.g. "service.name".
+	Resource map[string]string
+	// Gatherer is the registry to export, prometheus.DefaultGatherer if
+	// nil.
+	Gatherer prometheus.Gatherer
+	// Start is the start time of counters, i.e. when the process started.
+	Start time.Time
+	// HTTPClient makes the requests, http.DefaultClient if nil.
+	HTTPClient *http.Client
+	// Firmware adds the firmware version read with each Result as a
+	// resource attribute, so it's only set when the registry describes a
+	// single modem.
+	Firmware bool
+}
+
+// Endpoint returns the URL metrics are pushed to for a collector's base
+// endpoint, as with OTEL_EXPORTER_OTLP_ENDPOINT: v1/metrics is appended to
+// its path, so "http://localhost:4318" pushes to
+// "http://localhost:4318/v1/metrics" and "http://gw/otlp" to
+// "http://gw/otlp/v1/metrics".
+func Endpoint(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("OTLP endpoint %q must be an http or https URL", endpoint)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/v1/metrics"
+	u.RawPath = ""
+	return u.String(), nil
+}
+
+// ParseAttributes parses comma separated key=value pairs, as in
+// OTEL_RESOURCE_ATTRIBUTES and OTEL_EXPORTER_OTLP_HEADERS, into m.  Values
+// may be URL escaped.
+func ParseAttributes(m map[string]string, s string) error {
+	for _, kv := range strings.Split(s, ",") {
+		if strings.TrimSpace(kv) == "" {
+			continue
+		}
+		i := strings.Index(kv, "=")
+		if i < 0 {
+			return fmt.Errorf("expected key=value, got %q", kv)
+		}
+		v, err := url.QueryUnescape(strings.TrimSpace(kv[i+1:]))
+		if err != nil {
+			return err
+		}
+		m[strings.TrimSpace(kv[:i])] = v
+	}
+	return nil
+}
+
+// Export gathers the registry and pushes it, timestamped t.
+func (e *Exporter) Export(ctx context.Context, t time.Time) error {
+	return e.export(ctx, t, e.Resource)
+}
+
+func (e *Exporter) export(ctx context.Context, t time.Time, res map[string]string) error {
+	g := e.Gatherer
+	if g == nil {
+		g = prometheus.DefaultGatherer
+	}
+	mfs, err := g.Gather()
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(encode(mfs, res, e.Start, t))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", e.URL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.Headers {
+		req.Header.Set(k, v)
+	}
+	hc := e.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("POST %s: %s: %s", e.URL, resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// Write exports the registry after the poll giving r, so it should be added
+// to a sink.Dispatcher after the sink updating the registry.  If Firmware is
+// set, the modem's firmware version, if r has it, is added as the
+// modem.firmware resource attribute unless Resource sets one.
+func (e *Exporter) Write(ctx context.Context, r *sink.Result) error {
+	res := e.Resource
+	if e.Firmware && r.Signal != nil && r.Signal.Firmware != "" && res[firmwareAttribute] == "" {
+		res = map[string]string{firmwareAttribute: r.Signal.Firmware}
+		for k, v := range e.Resource {
+			res[k] = v
+		}
+	}
+	return e.export(ctx, r.Time, res)
+}
+
+// The types below follow the JSON mapping of the OTLP protobuf messages in
+// opentelemetry/proto/metrics/v1/metrics.proto.  64 bit integers are
+// strings.
+
+type request struct {
+	ResourceMetrics []resourceMetrics `json:"resourceMetrics"`
+}
+
+type resourceMetrics struct {
+	Resource     resource       `json:"resource"`
+	ScopeMetrics []scopeMetrics `json:"scopeMetrics"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeMetrics struct {
+	Scope   scope    `json:"scope"`
+	Metrics []metric `json:"metrics"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type metric struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Gauge       *gauge     `json:"gauge,omitempty"`
+	Sum         *sum       `json:"sum,omitempty"`
+	Summary     *summary   `json:"summary,omitempty"`
+	Histogram   *histogram `json:"histogram,omitempty"`
+}
+
+// cumulative is AGGREGATION_TEMPORALITY_CUMULATIVE.
+const cumulative = 2
+
+type gauge struct {
+	DataPoints []numberDataPoint `json:"dataPoints"`
+}
+
+type sum struct {
+	AggregationTemporality int               `json:"aggregationTemporality"`
+	IsMonotonic            bool              `json:"isMonotonic"`
+	DataPoints             []numberDataPoint `json:"dataPoints"`
+}
+
+type numberDataPoint struct {
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string     `json:"timeUnixNano"`
+	AsDouble          float64    `json:"asDouble"`
+}
+
+type summary struct {
+	DataPoints []summaryDataPoint `json:"dataPoints"`
+}
+
+type summaryDataPoint struct {
+	Attributes        []keyValue      `json:"attributes,omitempty"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string          `json:"timeUnixNano"`
+	Count             string          `json:"count"`
+	Sum               float64         `json:"sum"`
+	QuantileValues    []quantileValue `json:"quantileValues,omitempty"`
+}
+
+type quantileValue struct {
+	Quantile float64 `json:"quantile"`
+	Value    float64 `json:"value"`
+}
+
+type histogram struct {
+	AggregationTemporality int                  `json:"aggregationTemporality"`
+	DataPoints             []histogramDataPoint `json:"dataPoints"`
+}
+
+type histogramDataPoint struct {
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string     `json:"timeUnixNano"`
+	Count             string     `json:"count"`
+	Sum               float64    `json:"sum"`
+	BucketCounts      []string   `json:"bucketCounts"`
+	ExplicitBounds    []float64  `json:"explicitBounds"`
+}
+
+func nanos(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func attributes(m map[string]string) []keyValue {
+	var kvs []keyValue
+	for k, v := range m {
+		kvs = append(kvs, keyValue{Key: k, Value: anyValue{StringValue: v}})
+	}
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+	return kvs
+}
+
+func labels(m *dto.Metric) []keyValue {
+	var kvs []keyValue
+	for _, l := range m.GetLabel() {
+		kvs = append(kvs, keyValue{Key: l.GetName(), Value: anyValue{StringValue: l.GetValue()}})
+	}
+	return kvs
+}
+
+// encode converts prometheus metric families to an OTLP export request.
+// Gauges and untyped metrics become gauges, counters monotonic cumulative
+// sums, and summaries and histograms their OTLP equivalents.
+func encode(mfs []*dto.MetricFamily, res map[string]string, start, t time.Time) request {
+	var ms []metric
+	ts := nanos(t)
+	st := nanos(start)
+	for _, mf := range mfs {
+		m := metric{Name: mf.GetName(), Description: mf.GetHelp()}
+		switch mf.GetType() {
+		case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+			m.Gauge = &gauge{}
+			for _, pm := range mf.GetMetric() {
+				v := pm.GetGauge().GetValue()
+				if mf.GetType() == dto.MetricType_UNTYPED {
+					v = pm.GetUntyped().GetValue()
+				}
+				m.Gauge.DataPoints = append(m.Gauge.DataPoints, numberDataPoint{
+					Attributes:   labels(pm),
+					TimeUnixNano: ts,
+					AsDouble:     v,
+				})
+			}
+		case dto.MetricType_COUNTER:
+			m.Sum = &sum{AggregationTemporality: cumulative, IsMonotonic: true}
+			for _, pm := range mf.GetMetric() {
+				m.Sum.DataPoints = append(m.Sum.DataPoints, numberDataPoint{
+					Attributes:        labels(pm),
+					StartTimeUnixNano: st,
+					TimeUnixNano:      ts,
+					AsDouble:          pm.GetCounter().GetValue(),
+				})
+			}
+		case dto.MetricType_SUMMARY:
+			m.Summary = &summary{}
+			for _, pm := range mf.GetMetric() {
+				s := pm.GetSummary()
+				dp := summaryDataPoint{
+					Attributes:        labels(pm),
+					StartTimeUnixNano: st,
+					TimeUnixNano:      ts,
+					Count:             strconv.FormatUint(s.GetSampleCount(), 10),
+					Sum:               s.GetSampleSum(),
+				}
+				for _, q := range s.GetQuantile() {
+					dp.QuantileValues = append(dp.QuantileValues, quantileValue{Quantile: q.GetQuantile(), Value: q.GetValue()})
+				}
+				m.Summary.DataPoints = append(m.Summary.DataPoints, dp)
+			}
+		case dto.MetricType_HISTOGRAM:
+			m.Histogram = &histogram{AggregationTemporality: cumulative}
+			for _, pm := range mf.GetMetric() {
+				h := pm.GetHistogram()
+				dp := histogramDataPoint{
+					Attributes:        labels(pm),
+					StartTimeUnixNano: st,
+					TimeUnixNano:      ts,
+					Count:             strconv.FormatUint(h.GetSampleCount(), 10),
+					Sum:               h.GetSampleSum(),
+					BucketCounts:      []string{},
+					ExplicitBounds:    []float64{},
+				}
+				// Prometheus buckets are cumulative, OTLP's aren't, and
+				// OTLP's last bucket is implicitly unbounded.
+				var prev uint64
+				for _, b := range h.GetBucket() {
+					if math.IsInf(b.GetUpperBound(), 1) {
+						continue
+					}
+					dp.ExplicitBounds = append(dp.ExplicitBounds, b.GetUpperBound())
+					dp.BucketCounts = append(dp.BucketCounts, strconv.FormatUint(b.GetCumulativeCount()-prev, 10))
+					prev = b.GetCumulativeCount()
+				}
+				dp.BucketCounts = append(dp.BucketCounts, strconv.FormatUint(h.GetSampleCount()-prev, 10))
+				m.Histogram.DataPoints = append(m.Histogram.DataPoints, dp)
+			}
+		default:
+			continue
+		}
+		ms = append(ms, m)
+	}
+	return request{ResourceMetrics: []resourceMetrics{{
+		Resource: resource{Attributes: attributes(res)},
+		ScopeMetrics: []scopeMetrics{{
+			Scope:   scope{Name: "github.com/wathiede/surfer"},
+			Metrics: ms,
+		}},
+	}}}
+}
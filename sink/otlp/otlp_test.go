@@ -0,0 +1,196 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/sink"
+)
+
+func TestExport(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	snr := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "downstream_snr", Help: "SNR"}, []string{"channel"})
+	snr.WithLabelValues("3").Set(40.1)
+	reboots := prometheus.NewCounter(prometheus.CounterOpts{Name: "modem_reboots_total", Help: "Reboots"})
+	reboots.Add(2)
+	latency := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "latency_seconds", Help: "Latency", Buckets: []float64{0.1, 1}})
+	for _, v := range []float64{0.05, 0.5, 0.7, 5} {
+		latency.Observe(v)
+	}
+	reg.MustRegister(snr, reboots, latency)
+
+	var got request
+	var header string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/metrics" || r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		header = r.Header.Get("Api-Key")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}))
+	defer s.Close()
+
+	u, err := Endpoint(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start, now := time.Unix(100, 0), time.Unix(200, 0)
+	e := &Exporter{
+		URL:      u,
+		Headers:  map[string]string{"Api-Key": "secret"},
+		Resource: map[string]string{"service.name": "surfer", "modem.model": "SB8200"},
+		Gatherer: reg,
+		Start:    start,
+	}
+	if err := e.Export(context.Background(), now); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if header != "secret" {
+		t.Errorf("Header got %q want %q", header, "secret")
+	}
+
+	attr := func(k, v string) keyValue { return keyValue{Key: k, Value: anyValue{StringValue: v}} }
+	want := request{ResourceMetrics: []resourceMetrics{{
+		Resource: resource{Attributes: []keyValue{attr("modem.model", "SB8200"), attr("service.name", "surfer")}},
+		ScopeMetrics: []scopeMetrics{{
+			Scope: scope{Name: "github.com/wathiede/surfer"},
+			Metrics: []metric{
+				{Name: "downstream_snr", Description: "SNR", Gauge: &gauge{DataPoints: []numberDataPoint{
+					{Attributes: []keyValue{attr("channel", "3")}, TimeUnixNano: "200000000000", AsDouble: 40.1},
+				}}},
+				{Name: "latency_seconds", Description: "Latency", Histogram: &histogram{AggregationTemporality: cumulative, DataPoints: []histogramDataPoint{
+					{StartTimeUnixNano: "100000000000", TimeUnixNano: "200000000000", Count: "4", Sum: 6.25, BucketCounts: []string{"1", "2", "1"}, ExplicitBounds: []float64{0.1, 1}},
+				}}},
+				{Name: "modem_reboots_total", Description: "Reboots", Sum: &sum{AggregationTemporality: cumulative, IsMonotonic: true, DataPoints: []numberDataPoint{
+					{StartTimeUnixNano: "100000000000", TimeUnixNano: "200000000000", AsDouble: 2},
+				}}},
+			},
+		}},
+	}}}
+	if !reflect.DeepEqual(got, want) {
+		g, _ := json.MarshalIndent(got, "", "  ")
+		w, _ := json.MarshalIndent(want, "", "  ")
+		t.Errorf("Got:\n%s\nWant:\n%s", g, w)
+	}
+
+	e.URL = s.URL + "/wrong"
+	if err := e.Export(context.Background(), now); err == nil {
+		t.Errorf("Expected error from rejected export")
+	}
+}
+
+func TestParseAttributes(t *testing.T) {
+	got := map[string]string{"service.name": "surfer"}
+	if err := ParseAttributes(got, "modem.firmware=8200-18.2.9, site = home%20office,"); err != nil {
+		t.Fatalf("ParseAttributes failed: %v", err)
+	}
+	want := map[string]string{"service.name": "surfer", "modem.firmware": "8200-18.2.9", "site": "home office"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v want %v", got, want)
+	}
+	if err := ParseAttributes(got, "novalue"); err == nil {
+		t.Errorf("Expected error for missing value")
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"http://localhost:4318", "http://localhost:4318/v1/metrics"},
+		{"http://localhost:4318/", "http://localhost:4318/v1/metrics"},
+		{"http://gw/otlp", "http://gw/otlp/v1/metrics"},
+		{"https://gw/otlp/", "https://gw/otlp/v1/metrics"},
+	} {
+		got, err := Endpoint(tc.in)
+		if err != nil {
+			t.Errorf("Endpoint(%q) failed: %v", tc.in, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("Endpoint(%q) got %q want %q", tc.in, got, tc.want)
+		}
+	}
+	for _, in := range []string{"localhost:4318", "grpc://localhost:4317", "http://%zz"} {
+		if got, err := Endpoint(in); err == nil {
+			t.Errorf("Endpoint(%q) got %q want error", in, got)
+		}
+	}
+}
+
+func TestWriteFirmware(t *testing.T) {
+	var got map[string]string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		got = map[string]string{}
+		for _, kv := range req.ResourceMetrics[0].Resource.Attributes {
+			got[kv.Key] = kv.Value.StringValue
+		}
+	}))
+	defer s.Close()
+
+	e := &Exporter{
+		URL:      s.URL,
+		Resource: map[string]string{"service.name": "surfer"},
+		Gatherer: prometheus.NewRegistry(),
+		Firmware: true,
+	}
+	r := &sink.Result{Time: time.Unix(200, 0), Signal: &modem.Signal{Firmware: "AB01.01.009.32"}}
+	if err := e.Write(context.Background(), r); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if want := map[string]string{"service.name": "surfer", "modem.firmware": "AB01.01.009.32"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got resource %v want %v", got, want)
+	}
+	if _, ok := e.Resource["modem.firmware"]; ok {
+		t.Errorf("Write changed the Exporter's Resource")
+	}
+
+	// A configured firmware version wins.
+	e.Resource["modem.firmware"] = "configured"
+	if err := e.Write(context.Background(), r); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if got["modem.firmware"] != "configured" {
+		t.Errorf("Got modem.firmware %q want %q", got["modem.firmware"], "configured")
+	}
+
+	// Without Firmware, e.g. exporting several modems, none is added.
+	delete(e.Resource, "modem.firmware")
+	e.Firmware = false
+	if err := e.Write(context.Background(), r); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if want := map[string]string{"service.name": "surfer"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got resource %v want %v", got, want)
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package prom exports the result of each poll as prometheus metrics.
+package prom
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/modem/errorrate"
+	"github.com/wathiede/surfer/sink"
+)
+
+// Sink sets prometheus metrics from each Result.  It's safe for concurrent
+// use, so it can be shared by the Dispatchers before and after a reload.
+type Sink struct {
+	mu sync.Mutex
+
+	downstreamSNR        *prometheus.GaugeVec
+	downstreamPowerLevel *prometheus.GaugeVec
+
+	codewordsUnerrored     *prometheus.GaugeVec
+	codewordsCorrectable   *prometheus.GaugeVec
+	codewordsUncorrectable *prometheus.GaugeVec
+
+	codewordsCorrectableRate   *prometheus.GaugeVec
+	codewordsUncorrectableRate *prometheus.GaugeVec
+	codewordErrorRatio         *prometheus.GaugeVec
+
+	upstreamSymbolRate *prometheus.GaugeVec
+	upstreamPowerLevel *prometheus.GaugeVec
+
+	channelHealth *prometheus.GaugeVec
+	modemHealth   prometheus.Gauge
+
+	channelChanges *prometheus.CounterVec
+
+	reboots    prometheus.Counter
+	lastReboot prometheus.Gauge
+
+	fetchErrors    prometheus.Gauge
+	fetchSuccesses prometheus.Gauge
+
+	rates *errorrate.Tracker
+	prev  *modem.Signal
+
+	// collectors are the metrics registered by New.
+	collectors []prometheus.Collector
+}
+
+// New returns a Sink with its metrics registered with r.
+func New(r prometheus.Registerer) (*Sink, error) {
+	s := &Sink{
+		downstreamSNR: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "downstream_snr",
+			Help: "Downstream signal-to-noise ratio in dB",
+		},
+			[]string{"channel", "frequency_hz", "modulation"},
+		),
+		downstreamPowerLevel: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "downstream_power_level",
+			Help: "Downstream power level reading in dBmV",
+		},
+			[]string{"channel", "frequency_hz", "modulation"},
+		),
+
+		codewordsUnerrored: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "codewords_unerrored",
+			Help: "Unerrored codeword count",
+		},
+			[]string{"channel"},
+		),
+		codewordsCorrectable: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "codewords_correctable",
+			Help: "Correctable codeword count",
+		},
+			[]string{"channel"},
+		),
+		codewordsUncorrectable: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "codewords_uncorrectable",
+			Help: "Uncorrectable codeword count",
+		},
+			[]string{"channel"},
+		),
+
+		codewordsCorrectableRate: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "codewords_correctable_per_second",
+			Help: "Correctable codewords per second since the previous poll",
+		},
+			[]string{"channel"},
+		),
+		codewordsUncorrectableRate: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "codewords_uncorrectable_per_second",
+			Help: "Uncorrectable codewords per second since the previous poll",
+		},
+			[]string{"channel"},
+		),
+		codewordErrorRatio: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "codeword_error_ratio",
+			Help: "Fraction of codewords since the previous poll that were uncorrectable, only for modems reporting unerrored codewords",
+		},
+			[]string{"channel"},
+		),
+
+		upstreamSymbolRate: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "upstream_symbol_rate",
+			Help: "Upstream symbol rate in sym/sec",
+		},
+			[]string{"channel", "frequency_hz", "modulation", "ranging_status"},
+		),
+		upstreamPowerLevel: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "upstream_power_level",
+			Help: "Upstream power level reading in dBmV",
+		},
+			[]string{"channel", "frequency_hz", "modulation", "ranging_status"},
+		),
+
+		channelHealth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "channel_health",
+			Help: "Channel health graded against DOCSIS thresholds: 0 good, 1 marginal, 2 bad",
+		},
+			[]string{"channel", "direction"},
+		),
+		modemHealth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "modem_health",
+			Help: "Worst channel health: 0 good, 1 marginal, 2 bad",
+		}),
+
+		channelChanges: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "channel_changes_total",
+			Help: "Count of channels added, removed, or re-assigned a frequency or modulation by the CMTS",
+		},
+			[]string{"direction", "kind"},
+		),
+
+		reboots: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "modem_reboots_total",
+			Help: "Count of modem reboots detected since surfer started",
+		}),
+		lastReboot: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "modem_last_reboot_timestamp_seconds",
+			Help: "Unix time of the last detected modem reboot",
+		}),
+
+		fetchErrors: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "fetch_errors",
+			Help: "Count of errors when fetching metrics from modem.",
+		}),
+		fetchSuccesses: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "fetch_successes",
+			Help: "Count of successes when fetching metrics from modem.",
+		}),
+
+		rates: errorrate.NewTracker(),
+	}
+	s.collectors = []prometheus.Collector{
+		s.downstreamSNR,
+		s.downstreamPowerLevel,
+		s.upstreamSymbolRate,
+		s.upstreamPowerLevel,
+		s.codewordsUnerrored,
+		s.codewordsCorrectable,
+		s.codewordsUncorrectable,
+		s.codewordsCorrectableRate,
+		s.codewordsUncorrectableRate,
+		s.codewordErrorRatio,
+		s.channelHealth,
+		s.modemHealth,
+		s.channelChanges,
+		s.reboots,
+		s.lastReboot,
+		s.fetchErrors,
+		s.fetchSuccesses,
+	}
+	for i, c := range s.collectors {
+		if err := r.Register(c); err != nil {
+			for _, c := range s.collectors[:i] {
+				r.Unregister(c)
+			}
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Unregister removes the metrics from r, the Registerer passed to New, so
+// a Sink for the same metrics can be registered again.
+func (p *Sink) Unregister(r prometheus.Registerer) {
+	for _, c := range p.collectors {
+		r.Unregister(c)
+	}
+}
+
+// Write updates the metrics from r.
+func (p *Sink) Write(_ context.Context, r *sink.Result) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if r.Err != nil {
+		p.fetchErrors.Inc()
+		return nil
+	}
+	s := r.Signal
+	for ch, d := range s.Downstream {
+		p.downstreamSNR.WithLabelValues(string(ch), d.Frequency, d.Modulation).Set(d.SNR)
+		p.downstreamPowerLevel.WithLabelValues(string(ch), d.Frequency, d.Modulation).Set(d.PowerLevel)
+		p.codewordsUnerrored.WithLabelValues(string(ch)).Set(d.Unerrored)
+		p.codewordsCorrectable.WithLabelValues(string(ch)).Set(d.Correctable)
+		p.codewordsUncorrectable.WithLabelValues(string(ch)).Set(d.Uncorrectable)
+	}
+	if p.prev != nil {
+		p.deleteStale(p.prev, s)
+	}
+	p.prev = s
+	for _, e := range r.ChannelEvents {
+		p.channelChanges.WithLabelValues(e.Direction, string(e.Kind)).Inc()
+	}
+	if e := r.Reboot; e != nil {
+		p.reboots.Inc()
+		p.lastReboot.Set(float64(e.Time.Unix()))
+	}
+	for ch, rate := range p.rates.Update(r.Time, s) {
+		p.codewordsCorrectableRate.WithLabelValues(string(ch)).Set(rate.CorrectablePerSecond())
+		p.codewordsUncorrectableRate.WithLabelValues(string(ch)).Set(rate.UncorrectablePerSecond())
+		if ratio, ok := rate.ErrorRatio(); ok {
+			p.codewordErrorRatio.WithLabelValues(string(ch)).Set(ratio)
+		}
+	}
+
+	for ch, u := range s.Upstream {
+		p.upstreamSymbolRate.WithLabelValues(string(ch), u.Frequency, u.Modulation, u.Status).Set(u.SymbolRate)
+		p.upstreamPowerLevel.WithLabelValues(string(ch), u.Frequency, u.Modulation, u.Status).Set(u.PowerLevel)
+	}
+
+	h := r.Health
+	for ch, c := range h.Downstream {
+		p.channelHealth.WithLabelValues(string(ch), "downstream").Set(float64(c.Grade))
+	}
+	for ch, c := range h.Upstream {
+		p.channelHealth.WithLabelValues(string(ch), "upstream").Set(float64(c.Grade))
+	}
+	p.modemHealth.Set(float64(h.Overall))
+	p.fetchSuccesses.Inc()
+	return nil
+}
+
+// deleteStale removes the series of channels in prev that are missing from
+// cur, or whose labels changed, so they don't linger with their last values.
+func (p *Sink) deleteStale(prev, cur *modem.Signal) {
+	for ch, d := range prev.Downstream {
+		c, ok := cur.Downstream[ch]
+		if ok && c.Frequency == d.Frequency && c.Modulation == d.Modulation {
+			continue
+		}
+		p.downstreamSNR.DeleteLabelValues(string(ch), d.Frequency, d.Modulation)
+		p.downstreamPowerLevel.DeleteLabelValues(string(ch), d.Frequency, d.Modulation)
+		if ok {
+			continue
+		}
+		p.codewordsUnerrored.DeleteLabelValues(string(ch))
+		p.codewordsCorrectable.DeleteLabelValues(string(ch))
+		p.codewordsUncorrectable.DeleteLabelValues(string(ch))
+		p.codewordsCorrectableRate.DeleteLabelValues(string(ch))
+		p.codewordsUncorrectableRate.DeleteLabelValues(string(ch))
+		p.codewordErrorRatio.DeleteLabelValues(string(ch))
+		p.channelHealth.DeleteLabelValues(string(ch), "downstream")
+	}
+	for ch, u := range prev.Upstream {
+		c, ok := cur.Upstream[ch]
+		if ok && c.Frequency == u.Frequency && c.Modulation == u.Modulation && c.Status == u.Status {
+			continue
+		}
+		p.upstreamSymbolRate.DeleteLabelValues(string(ch), u.Frequency, u.Modulation, u.Status)
+		p.upstreamPowerLevel.DeleteLabelValues(string(ch), u.Frequency, u.Modulation, u.Status)
+		if !ok {
+			p.channelHealth.DeleteLabelValues(string(ch), "upstream")
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prom
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/modem/changes"
+	"github.com/wathiede/surfer/modem/health"
+	"github.com/wathiede/surfer/modem/reboot"
+	"github.com/wathiede/surfer/sink"
+)
+
+var _ sink.Sink = &Sink{}
+
+func result(t time.Time, s *modem.Signal) *sink.Result {
+	return &sink.Result{Modem: "SB8200", Time: t, Signal: s, Health: health.DOCSIS().Evaluate(s)}
+}
+
+func TestWrite(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	p, err := New(reg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, err := New(reg); err == nil {
+		t.Errorf("Expected error registering metrics twice")
+	}
+
+	t0 := time.Unix(1600000000, 0)
+	ctx := context.Background()
+	p.Write(ctx, result(t0, &modem.Signal{
+		Downstream: map[modem.Channel]*modem.Downstream{
+			"1": {Frequency: "591000000", Modulation: "QAM256", PowerLevel: 1.5, SNR: 40, Correctable: 100},
+			"2": {Frequency: "597000000", Modulation: "QAM256", PowerLevel: 2, SNR: 39},
+		},
+		Upstream: map[modem.Channel]*modem.Upstream{
+			"3": {Frequency: "35600000", Modulation: "ATDMA", Status: "Locked", PowerLevel: 44, SymbolRate: 5120},
+		},
+	}))
+	// Channel 2 is dropped and channel 1 moves, so their old series are
+	// deleted.
+	t1 := t0.Add(10 * time.Second)
+	r := result(t1, &modem.Signal{
+		Downstream: map[modem.Channel]*modem.Downstream{
+			"1": {Frequency: "603000000", Modulation: "QAM256", PowerLevel: 1.5, SNR: 40, Correctable: 150},
+		},
+		Upstream: map[modem.Channel]*modem.Upstream{
+			"3": {Frequency: "35600000", Modulation: "ATDMA", Status: "Locked", PowerLevel: 44, SymbolRate: 5120},
+		},
+	})
+	r.ChannelEvents = []changes.Event{
+		{Time: t1, Direction: changes.Downstream, Channel: "1", Kind: changes.FrequencyChanged, Old: "591000000", New: "603000000"},
+		{Time: t1, Direction: changes.Downstream, Channel: "2", Kind: changes.Removed, Old: "597000000", New: "597000000"},
+	}
+	r.Reboot = &reboot.Event{Time: t0.Add(5 * time.Second), Reason: "uptime went backwards"}
+	p.Write(ctx, r)
+	p.Write(ctx, &sink.Result{Modem: "SB8200", Time: t0.Add(20 * time.Second), Err: errors.New("timeout")})
+
+	want := `
+# HELP channel_changes_total Count of channels added, removed, or re-assigned a frequency or modulation by the CMTS
+# TYPE channel_changes_total counter
+channel_changes_total{direction="downstream",kind="frequency"} 1
+channel_changes_total{direction="downstream",kind="removed"} 1
+# HELP codewords_correctable_per_second Correctable codewords per second since the previous poll
+# TYPE codewords_correctable_per_second gauge
+codewords_correctable_per_second{channel="1"} 5
+# HELP downstream_snr Downstream signal-to-noise ratio in dB
+# TYPE downstream_snr gauge
+downstream_snr{channel="1",frequency_hz="603000000",modulation="QAM256"} 40
+# HELP fetch_errors Count of errors when fetching metrics from modem.
+# TYPE fetch_errors gauge
+fetch_errors 1
+# HELP fetch_successes Count of successes when fetching metrics from modem.
+# TYPE fetch_successes gauge
+fetch_successes 2
+# HELP modem_last_reboot_timestamp_seconds Unix time of the last detected modem reboot
+# TYPE modem_last_reboot_timestamp_seconds gauge
+modem_last_reboot_timestamp_seconds 1.600000005e+09
+# HELP modem_reboots_total Count of modem reboots detected since surfer started
+# TYPE modem_reboots_total counter
+modem_reboots_total 1
+# HELP upstream_power_level Upstream power level reading in dBmV
+# TYPE upstream_power_level gauge
+upstream_power_level{channel="3",frequency_hz="35600000",modulation="ATDMA",ranging_status="Locked"} 44
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want),
+		"channel_changes_total", "codewords_correctable_per_second", "downstream_snr",
+		"fetch_errors", "fetch_successes", "modem_last_reboot_timestamp_seconds", "modem_reboots_total",
+		"upstream_power_level"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUnregister(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	a := prometheus.WrapRegistererWith(prometheus.Labels{"modem": "a"}, reg)
+	b := prometheus.WrapRegistererWith(prometheus.Labels{"modem": "b"}, reg)
+	p, err := New(a)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, err := New(b); err != nil {
+		t.Fatalf("New with other labels failed: %v", err)
+	}
+	p.Unregister(a)
+	if _, err := New(a); err != nil {
+		t.Errorf("New after Unregister failed: %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sink defines where surfer sends the result of each poll of a
+// modem, and a Dispatcher fanning results out to several sinks.
+// Implementations live in the packages below.
+package sink
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/modem/changes"
+	"github.com/wathiede/surfer/modem/health"
+	"github.com/wathiede/surfer/modem/reboot"
+)
+
+// Result is the outcome of one poll of a modem.
+type Result struct {
+	// Modem is the name of the modem polled.
+	Modem string
+	// Time is when the poll finished.
+	Time time.Time
+	// Duration is how long the poll took.
+	Duration time.Duration
+	// Signal and Health are nil if the poll failed.
+	Signal *modem.Signal
+	Health *health.Report
+	// ChannelEvents are the channel changes since the previous successful
+	// poll, and Reboot is the reboot detected by this poll, or nil.
+	ChannelEvents []changes.Event
+	Reboot        *reboot.Event
+	// Err is why the poll failed.
+	Err error
+}
+
+// Sink receives the result of every poll.
+type Sink interface {
+	// Write sends r.  It should give up when ctx is done.
+	Write(ctx context.Context, r *Result) error
+}
+
+// dispatch is a Sink added to a Dispatcher.
+type dispatch struct {
+	name string
+	s    Sink
+	// queue, if not nil, feeds a goroutine writing to s.
+	queue chan *Result
+	done  chan struct{}
+	// cancel stops the goroutine's write in progress, and drops the rest
+	// of the queue.
+	cancel context.CancelFunc
+}
+
+// Dispatcher fans each Result out to its sinks.
+type Dispatcher struct {
+	// Timeout bounds each write to a sink.
+	Timeout time.Duration
+
+	// mu is held for reading while dispatching, so Close can't close a
+	// queue being sent to.
+	mu    sync.RWMutex
+	sinks []*dispatch
+}
+
+// NewDispatcher returns a Dispatcher with no sinks, timing out writes
+// after timeout.
+func NewDispatcher(timeout time.Duration) *Dispatcher {
+	return &Dispatcher{Timeout: timeout}
+}
+
+// Add adds s, named name in logs.  If queue is 0, s is written by Dispatch
+// itself, in the order added, so it should be quick, e.g. updating memory.
+// Otherwise s is written on its own goroutine, buffering up to queue Results,
+// so a slow or unreachable s doesn't hold up polling or other sinks.  When
+// the buffer is full, new Results are dropped.
+func (d *Dispatcher) Add(name string, s Sink, queue int) {
+	ds := &dispatch{name: name, s: s}
+	if queue > 0 {
+		ds.queue = make(chan *Result, queue)
+		ds.done = make(chan struct{})
+		var ctx context.Context
+		ctx, ds.cancel = context.WithCancel(context.Background())
+		go func() {
+			defer close(ds.done)
+			dropped := 0
+			for r := range ds.queue {
+				if ctx.Err() != nil {
+					dropped++
+					continue
+				}
+				d.write(ctx, ds, r)
+			}
+			if dropped > 0 {
+				glog.Warningf("Dropped %d results for %s on close", dropped, ds.name)
+			}
+		}()
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sinks = append(d.sinks, ds)
+}
+
+func (d *Dispatcher) write(ctx context.Context, ds *dispatch, r *Result) {
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout)
+	defer cancel()
+	if err := ds.s.Write(ctx, r); err != nil {
+		glog.Errorf("Failed to write to %s: %v", ds.name, err)
+	}
+}
+
+// Dispatch sends r to every sink.  It returns once the unqueued sinks have
+// written it.  After Close, Dispatch does nothing.
+func (d *Dispatcher) Dispatch(r *Result) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, ds := range d.sinks {
+		if ds.queue == nil {
+			d.write(context.Background(), ds, r)
+			continue
+		}
+		select {
+		case ds.queue <- r:
+		default:
+			glog.Warningf("Dropping result for %s, %d writes are pending", ds.name, cap(ds.queue))
+		}
+	}
+}
+
+// Close waits for queued Results to be written, then closes the sinks that
+// are io.Closers.  The sinks' queues are written at once.  If ctx is done
+// first, writes in progress are cancelled and the Results still queued are
+// dropped.  Results dispatched afterwards are dropped.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	sinks := d.sinks
+	d.sinks = nil
+	d.mu.Unlock()
+	for _, ds := range sinks {
+		if ds.queue != nil {
+			close(ds.queue)
+		}
+	}
+	drained := make(chan struct{})
+	defer close(drained)
+	go func() {
+		select {
+		case <-ctx.Done():
+			for _, ds := range sinks {
+				if ds.cancel != nil {
+					ds.cancel()
+				}
+			}
+		case <-drained:
+		}
+	}()
+	var first error
+	for _, ds := range sinks {
+		if ds.queue != nil {
+			<-ds.done
+			ds.cancel()
+		}
+		if c, ok := ds.s.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				glog.Errorf("Failed to close %s: %v", ds.name, err)
+				if first == nil {
+					first = err
+				}
+			}
+		}
+	}
+	return first
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder is a Sink recording the time of each Result written.
+type recorder struct {
+	mu     sync.Mutex
+	times  []int64
+	block  chan struct{}
+	closed bool
+	// order, if not nil, has the recorder's name appended on each write.
+	order *[]string
+	name  string
+}
+
+func (r *recorder) Write(ctx context.Context, res *Result) error {
+	if r.block != nil {
+		<-r.block
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.times = append(r.times, res.Time.Unix())
+	if r.order != nil {
+		*r.order = append(*r.order, r.name)
+	}
+	return res.Err
+}
+
+func (r *recorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestDispatcher(t *testing.T) {
+	d := NewDispatcher(time.Second)
+	var order []string
+	first := &recorder{order: &order, name: "first"}
+	second := &recorder{order: &order, name: "second"}
+	slow := &recorder{block: make(chan struct{})}
+	d.Add("first", first, 0)
+	d.Add("slow", slow, 2)
+	d.Add("second", second, 0)
+
+	// The slow sink's queue holds 2, and 1 more is blocked in Write, so
+	// the 4th result is dropped without holding up the others.
+	for i := int64(1); i <= 4; i++ {
+		d.Dispatch(&Result{Time: time.Unix(i, 0)})
+		if i == 1 {
+			// Wait for the slow sink to take the first result off
+			// its queue.
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+	d.Dispatch(&Result{Time: time.Unix(5, 0), Err: errors.New("poll failed")})
+	if want := []int64{1, 2, 3, 4, 5}; !reflect.DeepEqual(first.times, want) || !reflect.DeepEqual(second.times, want) {
+		t.Errorf("Inline sinks got %v and %v, want %v", first.times, second.times, want)
+	}
+	if want := []string{"first", "second", "first", "second"}; !reflect.DeepEqual(order[:4], want) {
+		t.Errorf("Inline sinks written in order %q, want %q", order[:4], want)
+	}
+
+	close(slow.block)
+	if err := d.Close(context.Background()); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if want := []int64{1, 2, 3}; !reflect.DeepEqual(slow.times, want) {
+		t.Errorf("Queued sink got %v, want %v", slow.times, want)
+	}
+	if !first.closed || !slow.closed || !second.closed {
+		t.Errorf("Expected all sinks closed")
+	}
+
+	// Dispatching after Close is dropped rather than sent on a closed
+	// queue.
+	d.Dispatch(&Result{Time: time.Unix(6, 0)})
+	if want := []int64{1, 2, 3, 4, 5}; !reflect.DeepEqual(first.times, want) {
+		t.Errorf("Inline sink got %v after Close, want %v", first.times, want)
+	}
+}
+
+// stuck is a Sink whose writes only return when cancelled.
+type stuck struct {
+	recorder
+}
+
+func (s *stuck) Write(ctx context.Context, res *Result) error {
+	s.recorder.Write(ctx, res)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestDispatcherCloseDeadline(t *testing.T) {
+	d := NewDispatcher(time.Hour)
+	s := &stuck{}
+	d.Add("stuck", s, 4)
+	for i := int64(1); i <= 4; i++ {
+		d.Dispatch(&Result{Time: time.Unix(i, 0)})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := d.Close(ctx); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Close took %s, want it to give up after 50ms", elapsed)
+	}
+	// The first write is cancelled, and the rest of the queue dropped.
+	if want := []int64{1}; !reflect.DeepEqual(s.times, want) {
+		t.Errorf("Stuck sink got %v, want %v", s.times, want)
+	}
+	if !s.closed {
+		t.Errorf("Expected the stuck sink closed")
+	}
+}
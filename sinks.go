@@ -0,0 +1,163 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/wathiede/surfer/sink"
+	"github.com/wathiede/surfer/sink/file"
+	"github.com/wathiede/surfer/sink/graphite"
+	"github.com/wathiede/surfer/sink/influx"
+	"github.com/wathiede/surfer/sink/jsonlog"
+	"github.com/wathiede/surfer/sink/mqtt"
+	"github.com/wathiede/surfer/sink/otlp"
+)
+
+var (
+	prometheusEnabled   = flag.Bool("prometheus", true, "serve prometheus metrics at /metrics, polling the modem on every scrape")
+	jsonLogPath         = flag.String("json_log", "", "file to append the result of every poll to as a line of JSON, or - for stdout.  Files are rotated, see -log_rotate_every.  (default) don't log results")
+	csvLogPath          = flag.String("csv_log", "", "file to append the result of every poll to as CSV, a row per channel.  Files are rotated, see -log_rotate_every.  (default) don't log results")
+	logRotateEvery      = flag.Duration("log_rotate_every", 24*time.Hour, "start a new -json_log or -csv_log file every period, e.g. at midnight UTC for 24h.  0 disables")
+	logMaxSize          = flag.Int("log_max_size", 100, "start a new -json_log or -csv_log file before it grows beyond this many megabytes.  0 disables")
+	logMaxBackups       = flag.Int("log_max_backups", 30, "number of rotated -json_log or -csv_log files to keep.  0 keeps all")
+	logCompress         = flag.Bool("log_compress", true, "gzip rotated -json_log and -csv_log files")
+	influxURL           = flag.String("influx_url", "", "base URL of an InfluxDB v2 server to write every polled status to, e.g. http://localhost:8086.  (default) don't write to InfluxDB")
+	influxOrg           = flag.String("influx_org", "", "InfluxDB organization to write to")
+	influxBucket        = flag.String("influx_bucket", "surfer", "InfluxDB bucket to write to")
+	influxToken         = flag.String("influx_token", "", "InfluxDB API token.  (default) the INFLUX_TOKEN environment variable")
+	mqttBroker          = flag.String("mqtt_broker", "", "host:port of an MQTT broker to publish every polled status to, with Home Assistant discovery.  (default) don't publish to MQTT")
+	mqttUsername        = flag.String("mqtt_username", "", "username for -mqtt_broker")
+	mqttPassword        = flag.String("mqtt_password", "", "password for -mqtt_broker, requires -mqtt_username")
+	mqttTopicPrefix     = flag.String("mqtt_topic_prefix", "surfer", "prefix of the MQTT topics status is published to")
+	mqttDiscoveryPrefix = flag.String("mqtt_discovery_prefix", "homeassistant", "Home Assistant's MQTT discovery prefix")
+	otlpEndpoint        = flag.String("otlp_endpoint", os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"), "OTLP/HTTP endpoint of an OpenTelemetry collector to push the prometheus metrics to after every poll, e.g. http://localhost:4318.  Requires -prometheus.  (default) don't push")
+	otlpHeaders         = flag.String("otlp_headers", os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"), "comma separated key=value headers sent with every OTLP push")
+	otlpResource        = flag.String("otlp_resource_attributes", os.Getenv("OTEL_RESOURCE_ATTRIBUTES"), "comma separated key=value resource attributes added to the OTLP metrics, e.g. site=home.  service.name, modem.model and, if the modem shows it, modem.firmware are set automatically")
+	graphiteAddr        = flag.String("graphite_addr", "", "host:port of a Graphite plaintext listener to send every polled status to, e.g. localhost:2003.  (default) don't send to Graphite")
+	statsdAddr          = flag.String("statsd_addr", "", "host:port of a StatsD server to send every polled status to as gauges, e.g. localhost:8125.  (default) don't send to StatsD")
+	graphitePrefix      = flag.String("graphite_prefix", "surfer", "first component of the paths sent to Graphite and StatsD")
+)
+
+const (
+	// sinkTimeout bounds each write to a sink.
+	sinkTimeout = 10 * time.Second
+	// sinkQueue is the number of results buffered for each sink writing
+	// over the network.
+	sinkQueue = 16
+)
+
+// newSinks returns a Dispatcher for the sinks enabled by c.  model is the
+// model of the modem polled, or empty if there are several.  The prometheus
+// metrics are kept per modem, so they're updated before dispatching.
+func newSinks(c *config, model string) (*sink.Dispatcher, error) {
+	d := sink.NewDispatcher(sinkTimeout)
+	// Results of named modems carry the name, which sinks shared by
+	// several modems add to what they write.
+	named := c.targets()[0].Name != ""
+	if o := c.Sinks.OTLP; o.Endpoint != "" {
+		u, err := otlp.Endpoint(o.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		e := &otlp.Exporter{
+			URL:        u,
+			Headers:    map[string]string{},
+			Resource:   map[string]string{"service.name": "surfer"},
+			Start:      time.Now(),
+			HTTPClient: &http.Client{},
+			Firmware:   model != "",
+		}
+		if model != "" {
+			e.Resource["modem.model"] = model
+		}
+		for k, v := range o.Headers {
+			e.Headers[k] = v
+		}
+		for k, v := range o.ResourceAttributes {
+			e.Resource[k] = v
+		}
+		// The export runs from the queue, so it gathers the registry
+		// as of the latest poll when it runs, which may be newer than
+		// the poll it was queued for.  With several modems, each poll
+		// exports them all.
+		d.Add("OTLP", e, sinkQueue)
+	}
+	if c.Sinks.JSONLog == "-" {
+		// Hide os.Stdout's Close, so closing the sinks on reload
+		// doesn't close it.
+		d.Add("JSON log", jsonlog.New(struct{ io.Writer }{os.Stdout}), sinkQueue)
+	}
+	rot := c.Sinks.LogRotation
+	for _, l := range []struct {
+		path   string
+		format file.Format
+	}{
+		{c.Sinks.JSONLog, file.JSONLines},
+		{c.Sinks.CSVLog, file.CSV},
+	} {
+		if l.path == "" || l.path == "-" {
+			continue
+		}
+		f, err := file.New(file.Options{
+			Path:        l.path,
+			Format:      l.format,
+			MaxSize:     int64(rot.MaxSize) << 20,
+			RotateEvery: rot.Every,
+			MaxBackups:  rot.MaxBackups,
+			Compress:    rot.Compress,
+		})
+		if err != nil {
+			d.Close(context.Background())
+			return nil, err
+		}
+		d.Add(l.path, f, sinkQueue)
+	}
+	if i := c.Sinks.Influx; i.URL != "" {
+		cl := &influx.Client{
+			URL:        i.URL,
+			Org:        i.Org,
+			Bucket:     i.Bucket,
+			Token:      i.Token,
+			HTTPClient: &http.Client{},
+		}
+		if cl.Token == "" {
+			cl.Token = os.Getenv("INFLUX_TOKEN")
+		}
+		d.Add("InfluxDB", cl, sinkQueue)
+	}
+	if q := c.Sinks.MQTT; q.Broker != "" {
+		d.Add("MQTT", mqtt.New(mqtt.Options{
+			Broker:          q.Broker,
+			Username:        q.Username,
+			Password:        q.Password,
+			TopicPrefix:     q.TopicPrefix,
+			DiscoveryPrefix: q.DiscoveryPrefix,
+			PerModem:        named,
+		}), sinkQueue)
+	}
+	if g := c.Sinks.Graphite; g.Addr != "" {
+		d.Add("Graphite", &graphite.Graphite{Addr: g.Addr, Prefix: g.Prefix, PerModem: named}, sinkQueue)
+	}
+	if g := c.Sinks.Graphite; g.StatsDAddr != "" {
+		d.Add("StatsD", &graphite.StatsD{Addr: g.StatsDAddr, Prefix: g.Prefix, PerModem: named}, sinkQueue)
+	}
+	return d, nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/modem/health"
+	"github.com/wathiede/surfer/sink/influx"
+)
+
+// status implements the "status" command, which detects the modem, fetches
+// its signal once and prints it to stdout.
+func status(ctx context.Context, c *config, args []string) error {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	format := fs.String("format", "table", "output format, one of: table, json, yaml, influx (line protocol, e.g. for Telegraf's exec input)")
+	name := fs.String("modem", "", "name of the modem in the config's modems.  (default) the first")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	mc, err := c.target(*name)
+	if err != nil {
+		return err
+	}
+	ctx = mc.context(ctx)
+	client := newClient(mc)
+	var write func(io.Writer, string, *modem.Signal, *health.Report) error
+	switch *format {
+	case "table":
+		write = writeTable
+	case "json":
+		write = writeJSON
+	case "yaml":
+		write = writeYAML
+	case "influx":
+		write = writeInflux
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	m := detectOnce(ctx, client, mc, c.Timeout)
+	if m == nil {
+		return fmt.Errorf("failed to find modem%s", describe(mc))
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+	s, err := m.Status(ctx, client)
+	if err != nil {
+		return err
+	}
+	return write(os.Stdout, m.Name(), s, c.thresholds.Evaluate(s))
+}
+
+// statusReport is the JSON form of a modem's status.
+type statusReport struct {
+	Modem string    `json:"modem"`
+	Time  time.Time `json:"time"`
+	*modem.Signal
+	Health *health.Report `json:"health"`
+	// Error is set when serving a previous status because the latest
+	// poll failed.
+	Error string `json:"error,omitempty"`
+}
+
+func writeJSON(w io.Writer, name string, s *modem.Signal, h *health.Report) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(statusReport{Modem: name, Time: time.Now(), Signal: s, Health: h})
+}
+
+// writeYAML writes the same fields as writeJSON, in the same order, as YAML.
+func writeYAML(w io.Writer, name string, s *modem.Signal, h *health.Report) error {
+	b, err := json.Marshal(statusReport{Modem: name, Time: time.Now(), Signal: s, Health: h})
+	if err != nil {
+		return err
+	}
+	// JSON is YAML, so decoding it keeps the JSON field names.  Clearing
+	// the styles turns the flow style of the JSON into block style.
+	var n yaml.Node
+	if err := yaml.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	var clearStyle func(n *yaml.Node)
+	clearStyle = func(n *yaml.Node) {
+		n.Style = 0
+		for _, c := range n.Content {
+			clearStyle(c)
+		}
+	}
+	clearStyle(&n)
+	e := yaml.NewEncoder(w)
+	e.SetIndent(2)
+	if err := e.Encode(&n); err != nil {
+		return err
+	}
+	return e.Close()
+}
+
+func writeInflux(w io.Writer, name string, s *modem.Signal, _ *health.Report) error {
+	return influx.Encode(w, name, time.Now(), s)
+}
+
+func writeTable(w io.Writer, name string, s *modem.Signal, h *health.Report) error {
+	fmt.Fprintf(w, "Modem: %s\n", name)
+	if s.Firmware != "" {
+		fmt.Fprintf(w, "Firmware: %s\n", s.Firmware)
+	}
+	if s.Uptime != 0 {
+		fmt.Fprintf(w, "Uptime: %s\n", s.Uptime.Round(time.Second))
+	}
+	fmt.Fprintf(w, "Health: %s\n\nDownstream\n", h.Overall)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Channel\tFrequency (Hz)\tModulation\tPower (dBmV)\tSNR (dB)\tUnerrored\tCorrectable\tUncorrectable\tHealth\t")
+	for _, ch := range s.DownstreamChannels() {
+		d := s.Downstream[ch]
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%.1f\t%.1f\t%.0f\t%.0f\t%.0f\t%s\t\n",
+			ch, d.Frequency, d.Modulation, d.PowerLevel, d.SNR, d.Unerrored, d.Correctable, d.Uncorrectable, h.Downstream[ch].Grade)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\nUpstream\n")
+	tw = tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Channel\tFrequency (Hz)\tModulation\tStatus\tSymbol rate (sym/s)\tPower (dBmV)\tHealth\t")
+	for _, ch := range s.UpstreamChannels() {
+		u := s.Upstream[ch]
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%.0f\t%.1f\t%s\t\n",
+			ch, u.Frequency, u.Modulation, u.Status, u.SymbolRate, u.PowerLevel, h.Upstream[ch].Grade)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	return writeReasons(w, h)
+}
+
+// writeReasons lists why channels graded worse than good.
+func writeReasons(w io.Writer, h *health.Report) error {
+	for _, dir := range []struct {
+		name     string
+		channels map[modem.Channel]*health.ChannelReport
+	}{
+		{"Downstream", h.Downstream},
+		{"Upstream", h.Upstream},
+	} {
+		var chs []modem.Channel
+		for ch, c := range dir.channels {
+			if len(c.Reasons) > 0 {
+				chs = append(chs, ch)
+			}
+		}
+		modem.SortChannels(chs)
+		for _, ch := range chs {
+			for _, r := range dir.channels[ch].Reasons {
+				if _, err := fmt.Fprintf(w, "%s %s %s\n", dir.name, ch, r); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/modem/health"
+)
+
+var testSignal = &modem.Signal{
+	Downstream: map[modem.Channel]*modem.Downstream{
+		"1":  {Frequency: "459000000", Modulation: "QAM256", PowerLevel: 2.4, SNR: 40.1, Correctable: 12, Uncorrectable: 3},
+		"10": {Frequency: "519000000", Modulation: "QAM256", PowerLevel: 8.5, SNR: 39.4},
+	},
+	Upstream: map[modem.Channel]*modem.Upstream{
+		"2": {Frequency: "30600000", Modulation: "ATDMA", Status: "Locked", SymbolRate: 5120000, PowerLevel: 42},
+	},
+	Uptime: 26*time.Hour + 3*time.Minute + 500*time.Millisecond,
+}
+
+func TestWriteTable(t *testing.T) {
+	h := health.DOCSIS().Evaluate(testSignal)
+	var buf bytes.Buffer
+	if err := writeTable(&buf, "SB8200", testSignal, h); err != nil {
+		t.Fatalf("writeTable failed: %v", err)
+	}
+	want := `Modem: SB8200
+Uptime: 26h3m1s
+Health: marginal
+
+Downstream
+  Channel  Frequency (Hz)  Modulation  Power (dBmV)  SNR (dB)  Unerrored  Correctable  Uncorrectable    Health
+        1       459000000      QAM256           2.4      40.1          0           12              3      good
+       10       519000000      QAM256           8.5      39.4          0            0              0  marginal
+
+Upstream
+  Channel  Frequency (Hz)  Modulation  Status  Symbol rate (sym/s)  Power (dBmV)  Health
+        2        30600000       ATDMA  Locked              5120000          42.0    good
+Downstream 10 marginal: power 8.5 dBmV outside -7 to 7
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	h := health.DOCSIS().Evaluate(testSignal)
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, "SB8200", testSignal, h); err != nil {
+		t.Fatalf("writeJSON failed: %v", err)
+	}
+	// Grades only marshal, so health is compared as generic JSON.
+	var got struct {
+		Modem string    `json:"modem"`
+		Time  time.Time `json:"time"`
+		*modem.Signal
+		Health interface{} `json:"health"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to decode %s: %v", buf.String(), err)
+	}
+	if got.Modem != "SB8200" || got.Time.IsZero() {
+		t.Errorf("Got modem %q time %s, want SB8200 and a time", got.Modem, got.Time)
+	}
+	if !reflect.DeepEqual(got.Signal, testSignal) {
+		t.Errorf("Signal got %+v want %+v", got.Signal, testSignal)
+	}
+	b, err := json.Marshal(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want interface{}
+	if err := json.Unmarshal(b, &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Health, want) {
+		t.Errorf("Health got %v want %v", got.Health, want)
+	}
+}
+
+func TestWriteYAML(t *testing.T) {
+	h := health.DOCSIS().Evaluate(testSignal)
+	var buf bytes.Buffer
+	if err := writeYAML(&buf, "SB8200", testSignal, h); err != nil {
+		t.Fatalf("writeYAML failed: %v", err)
+	}
+	// The YAML has the JSON field names, and strings that look like
+	// numbers stay strings.
+	var got struct {
+		Modem      string `yaml:"modem"`
+		Downstream map[string]struct {
+			Frequency  string  `yaml:"frequency"`
+			PowerLevel float64 `yaml:"power_level"`
+		} `yaml:"downstream"`
+		Health struct {
+			Overall string `yaml:"overall"`
+		} `yaml:"health"`
+	}
+	if err := yaml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to decode %s: %v", buf.String(), err)
+	}
+	d := got.Downstream["10"]
+	if got.Modem != "SB8200" || d.Frequency != "519000000" || d.PowerLevel != 8.5 || got.Health.Overall != "marginal" {
+		t.Errorf("Got %+v from:\n%s", got, buf.String())
+	}
+	if bytes.Contains(buf.Bytes(), []byte("{")) {
+		t.Errorf("Got flow style, want block style:\n%s", buf.String())
+	}
+}
@@ -13,10 +13,15 @@
 // limitations under the License.
 
 // Command surfer scrapes the signal status page of the following cable
-// modems and exports values as prometheus metrics.
+// modems and exports values as prometheus metrics, or prints them once with
+// the "status" command.
 // * SB6121
 // * SB6183
 // * SB8200
+// * Netgear CM600, CM1000, CM1200
+// * Technicolor TC4400
+// * Hitron CODA series
+// * AVM FRITZ!Box Cable (6490, 6591, 6660)
 
 package main
 
@@ -24,154 +29,149 @@ import (
 	"context"
 	"crypto/tls"
 	"flag"
+	"fmt"
 	"net/http"
 	_ "net/http/pprof"
+	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/golang/glog"
-	"github.com/golang/groupcache/singleflight"
-	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/wathiede/surfer/modem"
+	_ "github.com/wathiede/surfer/modem/fritzbox"
+	_ "github.com/wathiede/surfer/modem/hitron"
+	_ "github.com/wathiede/surfer/modem/netgear"
 	_ "github.com/wathiede/surfer/modem/sb6121"
 	_ "github.com/wathiede/surfer/modem/sb6183"
 	_ "github.com/wathiede/surfer/modem/sb8200"
+	"github.com/wathiede/surfer/modem/simulate"
+	_ "github.com/wathiede/surfer/modem/tc4400"
 )
 
 var (
 	port                  = flag.Int("port", 6666, "port to listen on when serving prometheus metrics")
 	timeout               = flag.Duration("timeout", 1*time.Second, "timeout for the HTTP GET to cable modem")
-	fakeDataPath          = flag.String("fake", "", "path to fake HTML data.  (default) fetch over HTTP")
+	fakeDataPath          = flag.String("fake", "", "path to fake data: an HTML file, a directory of pages keyed by URL path (e.g. written by the capture command), a .zip of one, or a directory of captures replayed in turn.  (default) fetch over HTTP")
+	fakeSimulate          = flag.Bool("fake_simulate", false, "with -fake, evolve the fake signal on every poll: jitter levels, advance codeword counters, and occasionally simulate reboots and channel loss")
 	tlsInsecureSkipVerify = flag.Bool("tls_insecure_skip_verify", false, "Whether to verify TLS certs")
-
-	downstreamSNRMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Name: "downstream_snr",
-		Help: "Downstream signal-to-noise ratio in dB",
-	},
-		[]string{"channel", "frequency_hz", "modulation"},
-	)
-	downstreamPowerLevelMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Name: "downstream_power_level",
-		Help: "Downstream power level reading in dBmV",
-	},
-		[]string{"channel", "frequency_hz", "modulation"},
-	)
-
-	codewordsUnerroredMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Name: "codewords_unerrored",
-		Help: "Unerrored codeword count",
-	},
-		[]string{"channel"},
-	)
-	codewordsCorrectableMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Name: "codewords_correctable",
-		Help: "Correctable codeword count",
-	},
-		[]string{"channel"},
-	)
-	codewordsUncorrectableMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Name: "codewords_uncorrectable",
-		Help: "Uncorrectable codeword count",
-	},
-		[]string{"channel"},
-	)
-
-	upstreamSymbolRateMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Name: "upstream_symbol_rate",
-		Help: "Upstream symbol rate in sym/sec",
-	},
-		[]string{"channel", "frequency_hz", "modulation", "ranging_status"},
-	)
-	upstreamPowerLevelMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Name: "upstream_power_level",
-		Help: "Upstream power level reading in dBmV",
-	},
-		[]string{"channel", "frequency_hz", "modulation", "ranging_status"},
-	)
-
-	fetchErrorsMetric = prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: "fetch_errors",
-		Help: "Count of errors when fetching metrics from modem.",
-	})
-
-	fetchSuccessesMetric = prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: "fetch_successes",
-		Help: "Count of successes when fetching metrics from modem.",
-	})
+	username              = flag.String("username", "", "username for modems whose status page requires a login")
+	password              = flag.String("password", "", "password for modems whose status page requires a login")
+	pollInterval          = flag.Duration("poll_interval", 0, "how often to poll the modem in the background, in addition to every /metrics request.  Set when nothing scrapes /metrics but history is wanted.  0 disables")
+	historyWindow         = flag.Duration("history_window", 24*time.Hour, "how long to keep signal history served by /api/v1/history")
+	historySamples        = flag.Int("history_samples", 10000, "most signal samples to keep for /api/v1/history")
+	storeDir              = flag.String("store_dir", "", "directory to persist signal history and events to, served by /api/v1/history and /api/v1/events and read by the history command.  (default) keep history in memory only")
+	storeRetention        = flag.Duration("store_retention", 7*24*time.Hour, "how long to keep history in -store_dir")
+	healthThresholdsPath  = flag.String("health_thresholds", "", "path to a JSON file overriding the DOCSIS thresholds used to grade channel health")
+	shutdownTimeout       = flag.Duration("shutdown_timeout", 10*time.Second, "on SIGINT or SIGTERM, how long to wait for requests in flight and then for queued results to be written to the outputs.  Results still queued after it are dropped")
+	configPath            = flag.String("config", "", "path to a YAML config file setting any of the other flags, or several modems, see README.md.  Flags given on the command line override it.  While serving, the file is read again on SIGHUP or a POST to /-/reload")
 )
 
-func init() {
-	prometheus.MustRegister(downstreamSNRMetric)
-	prometheus.MustRegister(downstreamPowerLevelMetric)
-	prometheus.MustRegister(upstreamSymbolRateMetric)
-	prometheus.MustRegister(upstreamPowerLevelMetric)
-	prometheus.MustRegister(codewordsUnerroredMetric)
-	prometheus.MustRegister(codewordsCorrectableMetric)
-	prometheus.MustRegister(codewordsUncorrectableMetric)
-	prometheus.MustRegister(fetchErrorsMetric)
-	prometheus.MustRegister(fetchSuccessesMetric)
-}
+// maxChannelEvents is the number of channel changes served by
+// /api/v1/channel_events.
+const maxChannelEvents = 1000
 
-func main() {
-	flag.Parse()
-	defer glog.Flush()
-	client := &http.Client{
+// detectRetry is how long detect waits between probes, a variable for
+// tests.
+var detectRetry = 5 * time.Second
+
+func newClient(m modemConfig) *http.Client {
+	return &http.Client{
 		Transport: &http.Transport{
 			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: *tlsInsecureSkipVerify,
+				InsecureSkipVerify: m.TLSInsecureSkipVerify,
 			},
 		},
 		Timeout: 10 * time.Second,
 	}
+}
 
-	ctx := context.Background()
-	var m modem.Modem
+// detect probes for a supported modem configured by m, retrying every
+// detectRetry until one is found.  It returns nil if ctx is done first.
+func detect(ctx context.Context, client *http.Client, m modemConfig, timeout time.Duration) modem.Modem {
 	for {
-		ctx, cancel := context.WithTimeout(ctx, *timeout)
-		m = modem.New(ctx, *client, *fakeDataPath)
-		cancel()
-		if m != nil {
-			break
+		if d := detectOnce(ctx, client, m, timeout); d != nil {
+			return d
+		}
+		glog.Infof("Failed to find modem%s, sleeping", describe(m))
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(detectRetry):
 		}
-		glog.Infof("Failed to find modem, sleeping")
-		time.Sleep(5 * time.Second)
 	}
-	glog.Infof("Found modem %q", m.Name())
-
-	g := &singleflight.Group{}
-	ph := promhttp.Handler()
-	// Refresh data every prometheus poll.
-	http.Handle("/metrics", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Only make one query to the cable modem if concurrent requests come in.
-		if _, err := g.Do("get", func() (interface{}, error) {
-			ctx, cancel := context.WithTimeout(ctx, *timeout)
-			defer cancel()
-			s, err := m.Status(ctx, *client)
-			if err != nil {
-				fetchErrorsMetric.Inc()
-				return nil, err
-			}
-			for ch, d := range s.Downstream {
-				downstreamSNRMetric.WithLabelValues(string(ch), d.Frequency, d.Modulation).Set(d.SNR)
-				downstreamPowerLevelMetric.WithLabelValues(string(ch), d.Frequency, d.Modulation).Set(d.PowerLevel)
-				codewordsUnerroredMetric.WithLabelValues(string(ch)).Set(d.Unerrored)
-				codewordsCorrectableMetric.WithLabelValues(string(ch)).Set(d.Correctable)
-				codewordsUncorrectableMetric.WithLabelValues(string(ch)).Set(d.Uncorrectable)
-			}
-
-			for ch, u := range s.Upstream {
-				upstreamSymbolRateMetric.WithLabelValues(string(ch), u.Frequency, u.Modulation, u.Status).Set(u.SymbolRate)
-				upstreamPowerLevelMetric.WithLabelValues(string(ch), u.Frequency, u.Modulation, u.Status).Set(u.PowerLevel)
-			}
-			fetchSuccessesMetric.Inc()
-			return nil, nil
-		}); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+}
+
+// detectOnce probes for a supported modem configured by m, returning nil if
+// none is found within timeout.
+func detectOnce(ctx context.Context, client *http.Client, m modemConfig, timeout time.Duration) modem.Modem {
+	ctx, cancel := context.WithTimeout(m.context(ctx), timeout)
+	defer cancel()
+	d := modem.New(ctx, client, m.Fake)
+	if d != nil && m.Fake != "" && m.Simulate {
+		o := simulate.DefaultOptions()
+		o.Seed = time.Now().UnixNano()
+		d = simulate.New(d, o)
+	}
+	return d
+}
+
+// describe returns " <name>" for a named modem, to follow "modem" in logs
+// and errors, and "" otherwise.
+func describe(m modemConfig) string {
+	if m.Name == "" {
+		return ""
+	}
+	return " " + strconv.Quote(m.Name)
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	defer glog.Flush()
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		glog.Exitf("Failed to load config: %v", err)
+	}
+	// Cancelled on SIGINT or SIGTERM, so commands stop cleanly.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch cmd := flag.Arg(0); cmd {
+	case "":
+		if err := serve(ctx, cfg); err != nil {
+			glog.Exitf("serve: %v", err)
+		}
+	case "status":
+		if err := status(ctx, cfg, flag.Args()[1:]); err != nil {
+			glog.Exitf("status: %v", err)
+		}
+	case "capture":
+		if err := capture(ctx, cfg, flag.Args()[1:]); err != nil {
+			glog.Exitf("capture: %v", err)
 		}
-		ph.ServeHTTP(w, r)
-	}))
-	glog.Fatalf("Listener returned: %v", http.ListenAndServe(":"+strconv.Itoa(*port), nil))
+	case "history":
+		if err := showHistory(cfg, flag.Args()[1:]); err != nil {
+			glog.Exitf("history: %v", err)
+		}
+	default:
+		glog.Exitf("Unknown command %q, see -help", cmd)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), `Usage: %s [flags] [command]
+
+With no command, surfer serves prometheus metrics.  Commands:
+  status   print the modem's current signal and exit
+  capture  save the pages read from the modem as fake data for -fake
+  history  print the signal and events recorded in -store_dir
+
+Commands take -modem <name> to pick one of the config's modems, and -help.
+
+Flags:
+`, os.Args[0])
+	flag.PrintDefaults()
 }
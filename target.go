@@ -0,0 +1,233 @@
+// Copyright 2026 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/groupcache/singleflight"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/wathiede/surfer/modem"
+	"github.com/wathiede/surfer/modem/changes"
+	"github.com/wathiede/surfer/modem/history"
+	"github.com/wathiede/surfer/modem/reboot"
+	"github.com/wathiede/surfer/modem/store"
+	"github.com/wathiede/surfer/sink"
+	"github.com/wathiede/surfer/sink/prom"
+)
+
+// target is a modem being polled, with the history and events derived from
+// its status.
+type target struct {
+	// name is the modem's name in the config, empty if it has none.
+	name string
+
+	g             singleflight.Group
+	reboots       reboot.Detector
+	channelEvents *changes.Log
+	samples       *history.Ring
+	latest        latestStatus
+
+	// mu guards the fields below.  The config, client and modem are
+	// replaced by reload.
+	mu     sync.Mutex
+	cfg    modemConfig
+	client *http.Client
+	modem  modem.Modem
+	// store, if not nil, persists history.
+	store *store.Store
+	// prom, if not nil, holds the modem's prometheus metrics.  It's
+	// created the first time prometheus is enabled, and kept across
+	// reloads, as its metrics can only be registered once.
+	prom *prom.Sink
+}
+
+// newTarget returns a target polling m, found with client as configured by
+// mc, keeping history as configured by h.  If st isn't empty, history is
+// also persisted to a store under st.Dir.
+func newTarget(mc modemConfig, client *http.Client, m modem.Modem, h historyConfig, st storeConfig) (*target, error) {
+	if mc.Name == "" {
+		glog.Infof("Found modem %q", m.Name())
+	} else {
+		glog.Infof("Found modem %q as %q", m.Name(), mc.Name)
+	}
+	t := &target{
+		name:          mc.Name,
+		channelEvents: changes.NewLog(maxChannelEvents),
+		samples:       history.NewRing(h.Window, h.Samples),
+		cfg:           mc,
+		client:        client,
+		modem:         m,
+	}
+	if st.Dir != "" {
+		var err error
+		if t.store, err = store.Open(mc.storeDir(st.Dir), st.Retention); err != nil {
+			return nil, fmt.Errorf("failed to open store: %v", err)
+		}
+	}
+	return t, nil
+}
+
+// config returns the config of t's modem.
+func (t *target) config() modemConfig {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cfg
+}
+
+// set replaces the modem polled, after its config changed.
+func (t *target) set(mc modemConfig, client *http.Client, m modem.Modem) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg, t.client, t.modem = mc, client, m
+}
+
+// label returns what t's modem is called in outputs: its name in the
+// config, or else its model.
+func (t *target) label(m modem.Modem) string {
+	if t.name != "" {
+		return t.name
+	}
+	return m.Name()
+}
+
+// registerer returns r, adding a modem label with t's name if it has one.
+func (t *target) registerer(r prometheus.Registerer) prometheus.Registerer {
+	if t.name == "" {
+		return r
+	}
+	return prometheus.WrapRegistererWith(prometheus.Labels{"modem": t.name}, r)
+}
+
+// register creates t's prometheus metrics in r, if not yet created.
+func (t *target) register(r prometheus.Registerer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.prom != nil {
+		return nil
+	}
+	p, err := prom.New(t.registerer(r))
+	if err != nil {
+		return err
+	}
+	t.prom = p
+	return nil
+}
+
+// unregister removes t's prometheus metrics, if any, from r.
+func (t *target) unregister(r prometheus.Registerer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.prom != nil {
+		t.prom.Unregister(t.registerer(r))
+		t.prom = nil
+	}
+}
+
+// historyStore returns the store of t's history, or nil if there isn't
+// one.
+func (t *target) historyStore() *store.Store {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.store
+}
+
+// record persists r if there's a store.
+func (t *target) record(r store.Record) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.store == nil {
+		return
+	}
+	if err := t.store.Add(r); err != nil {
+		glog.Errorf("Failed to store history: %v", err)
+	}
+}
+
+// close closes t's store, if any.  Polls after it no longer persist
+// history.
+func (t *target) close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.store == nil {
+		return nil
+	}
+	err := t.store.Close()
+	t.store = nil
+	return err
+}
+
+// poll queries the modem, updates everything derived from its status, and
+// sends the result to the modem's prometheus metrics, if cfg enables them,
+// then to sinks.
+func (t *target) poll(ctx context.Context, cfg *config, sinks *sink.Dispatcher) error {
+	// Only make one query to the cable modem if concurrent requests come in.
+	_, err := t.g.Do("get", func() (interface{}, error) {
+		t.mu.Lock()
+		mc, client, m, p := t.cfg, t.client, t.modem, t.prom
+		t.mu.Unlock()
+		if !cfg.Sinks.Prometheus {
+			p = nil
+		}
+		dispatch := func(r *sink.Result) {
+			// The metrics are updated first, as the OTLP
+			// exporter reads them.
+			if p != nil {
+				p.Write(ctx, r)
+			}
+			sinks.Dispatch(r)
+		}
+
+		ctx, cancel := context.WithTimeout(mc.context(ctx), cfg.Timeout)
+		defer cancel()
+		start := time.Now()
+		sig, err := m.Status(ctx, client)
+		now := time.Now()
+		name := t.label(m)
+		r := &sink.Result{Modem: name, Time: now, Duration: now.Sub(start), Signal: sig, Err: err}
+		if err != nil {
+			dispatch(r)
+			return nil, err
+		}
+		t.samples.Add(now, sig)
+		logPrefix := ""
+		if t.name != "" {
+			logPrefix = t.name + ": "
+		}
+		t.record(store.Record{Time: now, Signal: sig})
+		r.ChannelEvents = t.channelEvents.Update(now, sig)
+		for _, e := range r.ChannelEvents {
+			glog.Infof("%s%s channel %s %s %s -> %s", logPrefix, e.Direction, e.Channel, e.Kind, e.Old, e.New)
+			e := e
+			t.record(store.Record{Time: now, ChannelEvent: &e})
+		}
+		r.Reboot = t.reboots.Update(now, sig)
+		if e := r.Reboot; e != nil {
+			glog.Warningf("Modem%s rebooted at %s: %s", describe(mc), e.Time.Format(time.RFC3339), e.Reason)
+			t.record(store.Record{Time: now, Reboot: e})
+		}
+		r.Health = cfg.thresholds.Evaluate(sig)
+		t.latest.set(name, now, sig, r.Health)
+		dispatch(r)
+		return nil, nil
+	})
+	return err
+}
@@ -0,0 +1,148 @@
+// Dashboard for surfer: polls /api/v1/status for the current signal and
+// /api/v1/history for sparklines.
+'use strict';
+
+const params = new URLSearchParams(location.search);
+// Seconds between refreshes, and hours of history charted.
+const refresh = Number(params.get('refresh')) || 15;
+const hours = Number(params.get('hours')) || 6;
+// The named modem shown, or the first if empty.
+const modem = params.get('modem') || '';
+const modemParam = modem ? 'modem=' + encodeURIComponent(modem) : '';
+
+// sortChannels orders channels numerically where possible, like
+// modem.SortChannels.
+function sortChannels(chs) {
+  return chs.sort((a, b) => {
+    const x = Number(a), y = Number(b);
+    const xn = a !== '' && !isNaN(x), yn = b !== '' && !isNaN(y);
+    if (xn && yn) return x - y;
+    if (xn) return -1;
+    if (yn) return 1;
+    return a < b ? -1 : a > b ? 1 : 0;
+  });
+}
+
+function cell(tr, text, className) {
+  const td = document.createElement('td');
+  td.textContent = text;
+  if (className) td.className = className;
+  tr.appendChild(td);
+  return td;
+}
+
+function fixed(v) {
+  return v === undefined ? '' : v.toFixed(1);
+}
+
+function mhz(hz) {
+  const v = Number(hz);
+  return isNaN(v) || v === 0 ? hz : (v / 1e6).toFixed(1);
+}
+
+// sparkline returns an SVG polyline of values scaled to fit.
+function sparkline(values) {
+  const ns = 'http://www.w3.org/2000/svg';
+  const svg = document.createElementNS(ns, 'svg');
+  svg.setAttribute('class', 'spark');
+  svg.setAttribute('viewBox', '0 0 100 20');
+  svg.setAttribute('preserveAspectRatio', 'none');
+  if (values.length < 2) return svg;
+  const min = Math.min(...values), max = Math.max(...values);
+  const range = max - min || 1;
+  const points = values.map((v, i) =>
+    (i * 100 / (values.length - 1)).toFixed(1) + ',' +
+    (19 - (v - min) * 18 / range).toFixed(1));
+  const line = document.createElementNS(ns, 'polyline');
+  line.setAttribute('points', points.join(' '));
+  svg.appendChild(line);
+  const title = document.createElementNS(ns, 'title');
+  title.textContent = min.toFixed(1) + ' to ' + max.toFixed(1);
+  svg.appendChild(title);
+  return svg;
+}
+
+function gradeCell(tr, report) {
+  const grade = report ? report.grade : '';
+  const td = cell(tr, grade, 'grade ' + grade);
+  if (report && report.reasons) td.title = report.reasons.join('\n');
+}
+
+function chartCell(tr, points, key) {
+  const td = cell(tr, '');
+  td.appendChild(sparkline((points || []).map(p => p[key])));
+}
+
+function duration(ns) {
+  let s = Math.floor(ns / 1e9);
+  const d = Math.floor(s / 86400);
+  s -= d * 86400;
+  const h = Math.floor(s / 3600);
+  const m = Math.floor((s - h * 3600) / 60);
+  return (d ? d + 'd ' : '') + h + 'h ' + m + 'm';
+}
+
+function render(status, history) {
+  document.getElementById('modem').textContent = status.modem;
+  const health = document.getElementById('health');
+  health.textContent = status.health.overall;
+  health.className = 'grade ' + status.health.overall;
+  document.getElementById('uptime').textContent =
+    status.uptime ? 'up ' + duration(status.uptime) : '';
+  document.getElementById('updated').textContent =
+    'updated ' + new Date(status.time).toLocaleTimeString();
+  const error = document.getElementById('error');
+  error.hidden = !status.error;
+  error.textContent = status.error ? 'Last poll failed: ' + status.error : '';
+
+  const down = document.querySelector('#downstream tbody');
+  down.replaceChildren();
+  for (const ch of sortChannels(Object.keys(status.downstream || {}))) {
+    const d = status.downstream[ch];
+    const tr = document.createElement('tr');
+    cell(tr, ch);
+    cell(tr, mhz(d.frequency));
+    cell(tr, d.modulation);
+    cell(tr, fixed(d.power_level));
+    cell(tr, fixed(d.snr));
+    cell(tr, d.correctable);
+    cell(tr, d.uncorrectable);
+    gradeCell(tr, status.health.downstream[ch]);
+    chartCell(tr, history.downstream[ch], 'power_level');
+    chartCell(tr, history.downstream[ch], 'snr');
+    down.appendChild(tr);
+  }
+
+  const up = document.querySelector('#upstream tbody');
+  up.replaceChildren();
+  for (const ch of sortChannels(Object.keys(status.upstream || {}))) {
+    const u = status.upstream[ch];
+    const tr = document.createElement('tr');
+    cell(tr, ch);
+    cell(tr, mhz(u.frequency));
+    cell(tr, u.modulation);
+    cell(tr, fixed(u.power_level));
+    gradeCell(tr, status.health.upstream[ch]);
+    chartCell(tr, history.upstream[ch], 'power_level');
+    up.appendChild(tr);
+  }
+}
+
+async function update() {
+  try {
+    const from = Date.now() / 1000 - hours * 3600;
+    const [status, history] = await Promise.all([
+      fetch('api/v1/status' + (modemParam ? '?' + modemParam : '')).then(r => r.ok ? r.json() : r.text().then(t => Promise.reject(new Error(t)))),
+      fetch('api/v1/history?points=120&from=' + from + (modemParam ? '&' + modemParam : '')).then(r => r.json()),
+    ]);
+    render(status, history);
+  } catch (e) {
+    const error = document.getElementById('error');
+    error.hidden = false;
+    error.textContent = 'Failed to update: ' + e.message;
+  }
+}
+
+document.getElementById('span').textContent = hours + ' hours';
+update();
+setInterval(update, refresh * 1000);